@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCmd 生成 shell 补全脚本，替代 cobra 默认的 completion 命令
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "生成 shell 补全脚本",
+		Long: `生成 bash、zsh 或 fish 的补全脚本。除命令和参数外，还会动态补全
+user delete 的用户名（从用户存储读取）和 service 子命令 --name 的服务名（从服务定义目录读取）。
+
+加载方式：
+  bash:  source <(sysctl completion bash)
+         或 sysctl completion bash > /etc/bash_completion.d/sysctl
+  zsh:   sysctl completion zsh > "${fpath[1]}/_sysctl"
+  fish:  sysctl completion fish > ~/.config/fish/completions/sysctl.fish`,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, out := cmd.Root(), cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			}
+			return fmt.Errorf("不支持的 shell：%s", args[0])
+		},
+	}
+}
+
+// completeUserNames 补全已存在的用户名，已经输入过的不再出现。
+// 补全时 cobra 不执行 PersistentPreRunE，这里先按 命令行 > 环境变量 > 配置文件 确定 --store 等参数；
+// 补全过程中的错误不能输出到终端，出错时只是不提供候选项
+func completeUserNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := applyConfig(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := userStore(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer store.Close()
+	users, err := store.List(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, u := range users {
+		if strings.HasPrefix(u.Name, toComplete) && !slices.Contains(args, u.Name) {
+			names = append(names, u.Name+"\t"+strings.TrimSpace(u.Role+" "+u.Email))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServiceNames 补全服务定义目录中的服务名，与 completeUserNames 一样先确定参数取值，出错时不提供候选项
+func completeServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := applyConfig(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	d := getServiceDirs(cmd)
+	names, err := listServices(d.Services)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var result []string
+	for _, name := range names {
+		if !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		if def, err := loadService(d.Services, name); err == nil {
+			result = append(result, name+"\t"+strings.Join(append([]string{def.Command}, def.Args...), " "))
+		} else {
+			result = append(result, name)
+		}
+	}
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// 每个参数的取值按以下优先级确定：命令行参数 > 环境变量 > 配置文件 > 默认值。
+//
+// 配置文件（YAML 或 TOML）的键为参数名，也可以放在以子命令命名的小节中，只对该命令生效：
+//
+//	debug: true
+//	store: file:///var/lib/sysctl/users.json
+//	user:
+//	  list:
+//	    output: json
+//
+// 环境变量为 SYSCTL_ 加参数名（大写，- 换成 _），同样可以带上命令路径，如
+// SYSCTL_DEBUG、SYSCTL_USER_LIST_OUTPUT。两种方式都是越具体的越优先。
+//
+// scopedFlags 中的参数（确认、操作对象）只能写在完整的命令路径下，如 SYSCTL_USER_DELETE_YES、
+// service.start.name，避免顶层的一个 yes 或 name 同时作用于多个命令。
+
+// scopedFlags 只能按完整命令路径配置的参数
+var scopedFlags = map[string]bool{"yes": true, "name": true}
+
+// envPrefix 环境变量前缀
+const envPrefix = "SYSCTL_"
+
+// sourceAnnotation 记录参数值来源的 flag annotation，供 config view 和需要区分来源的命令使用
+const sourceAnnotation = "sysctl_source"
+
+const (
+	sourceDefault     = "默认值"
+	sourceCommandLine = "命令行参数"
+)
+
+// configFile 已加载的配置文件
+type configFile struct {
+	Path string
+	Data map[string]any
+}
+
+// defaultConfigPaths 未指定 --config 时依次查找的文件，都不存在时不使用配置文件
+func defaultConfigPaths() []string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	dir = filepath.Join(dir, "sysctl")
+	return []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "config.yml"),
+		filepath.Join(dir, "config.toml"),
+	}
+}
+
+// loadConfig 读取 --config 或 SYSCTL_CONFIG 指定的配置文件，都没有时查找默认位置
+func loadConfig(cmd *cobra.Command) (*configFile, error) {
+	path, _ := cmd.Flags().GetString("config")
+	if !cmd.Flags().Changed("config") {
+		path = os.Getenv(envPrefix + "CONFIG")
+	}
+	if path != "" {
+		return readConfig(path)
+	}
+	for _, p := range defaultConfigPaths() {
+		cfg, err := readConfig(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return cfg, err
+	}
+	return &configFile{}, nil
+}
+
+// readConfig 按扩展名解析 YAML（.yaml、.yml）或 TOML（.toml）
+func readConfig(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &configFile{Path: path}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg.Data)
+	case ".toml":
+		err = toml.Unmarshal(data, &cfg.Data)
+	default:
+		return nil, fmt.Errorf("配置文件 %s 的格式无法识别，扩展名应为 .yaml、.yml 或 .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate 检查配置项都对应某个参数或子命令，避免拼写错误被静默忽略
+func (c *configFile) validate(root *cobra.Command) error {
+	var check func(cmd *cobra.Command, data map[string]any, prefix string) error
+	check = func(cmd *cobra.Command, data map[string]any, prefix string) error {
+		known := subtreeFlags(cmd)
+		for key, v := range data {
+			if m, ok := v.(map[string]any); ok {
+				if sub := findSubcommand(cmd, key); sub != nil {
+					if err := check(sub, m, prefix+key+"."); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if !known[key] {
+				return fmt.Errorf("配置文件 %s 中有未知的配置项 %s%s", c.Path, prefix, key)
+			}
+			if scopedFlags[key] && cmd.LocalFlags().Lookup(key) == nil {
+				return fmt.Errorf("配置文件 %s 中的 %s%s 必须写在具体命令的小节中，如 user.delete.yes", c.Path, prefix, key)
+			}
+		}
+		return nil
+	}
+	return check(root, c.Data, "")
+}
+
+// subtreeFlags 命令及其所有子命令可用的参数名
+func subtreeFlags(cmd *cobra.Command) map[string]bool {
+	names := make(map[string]bool)
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.LocalFlags().VisitAll(func(f *pflag.Flag) { names[f.Name] = true })
+		c.InheritedFlags().VisitAll(func(f *pflag.Flag) { names[f.Name] = true })
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(cmd)
+	delete(names, "help")
+	return names
+}
+
+func findSubcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// lookup 在配置文件中查找参数，从最具体的小节（如 user.list）开始，逐级退回到 path[:lowest]
+func (c *configFile) lookup(path []string, lowest int, name string) (any, string, bool) {
+	for n := len(path); n >= lowest; n-- {
+		m := c.Data
+		for _, p := range path[:n] {
+			sub, ok := m[p].(map[string]any)
+			if !ok {
+				m = nil
+				break
+			}
+			m = sub
+		}
+		if v, ok := m[name]; ok {
+			return v, strings.Join(append(path[:n:n], name), "."), true
+		}
+	}
+	return nil, "", false
+}
+
+// envName 参数对应的环境变量名，如 (["user", "list"], "output") -> SYSCTL_USER_LIST_OUTPUT
+func envName(path []string, name string) string {
+	parts := append(append([]string{}, path...), name)
+	s := strings.ToUpper(strings.Join(parts, "_"))
+	return envPrefix + strings.ReplaceAll(s, "-", "_")
+}
+
+// resolved 参数的最终取值及来源
+type resolved struct {
+	Values []string // 数组类型的参数可能有多个值
+	Source string
+}
+
+// resolveFlag 按 命令行 > 环境变量 > 配置文件 > 默认值 确定参数取值，不修改参数本身
+func resolveFlag(cfg *configFile, path []string, f *pflag.Flag) (resolved, error) {
+	// applyConfig 赋值的参数也是 Changed，按 annotation 区分出真正在命令行中给出的
+	if src := f.Annotations[sourceAnnotation]; f.Changed && (len(src) == 0 || src[0] == sourceCommandLine) {
+		return resolved{Values: []string{f.Value.String()}, Source: sourceCommandLine}, nil
+	}
+	lowest := 0
+	if scopedFlags[f.Name] {
+		lowest = len(path)
+	}
+	for n := len(path); n >= lowest; n-- {
+		key := envName(path[:n], f.Name)
+		if v, ok := os.LookupEnv(key); ok {
+			return resolved{Values: []string{v}, Source: "环境变量 " + key}, nil
+		}
+	}
+	if v, key, ok := cfg.lookup(path, lowest, f.Name); ok {
+		values, err := configValues(v)
+		if err != nil {
+			return resolved{}, fmt.Errorf("配置文件 %s 中 %s 的值不正确: %w", cfg.Path, key, err)
+		}
+		return resolved{Values: values, Source: fmt.Sprintf("配置文件 %s（%s）", cfg.Path, key)}, nil
+	}
+	return resolved{Values: []string{f.DefValue}, Source: sourceDefault}, nil
+}
+
+// configValues 把配置文件中的值转换为参数的字符串形式；列表用于可重复的参数（如 --filter）
+func configValues(v any) ([]string, error) {
+	if list, ok := v.([]any); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			s, err := configScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	}
+	s, err := configScalar(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+func configScalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case map[string]any, []any, nil:
+		return "", fmt.Errorf("应为字符串、数字或布尔值")
+	}
+	return fmt.Sprint(v), nil
+}
+
+// commandPath 命令相对根命令的路径，如 sysctl user list -> ["user", "list"]
+func commandPath(cmd *cobra.Command) []string {
+	return strings.Fields(cmd.CommandPath())[1:]
+}
+
+// applyConfig 为当前命令的每个参数确定取值（根命令的 PersistentPreRunE 中调用，早于必需参数的检查）。
+// 来自环境变量或配置文件的值通过 FlagSet.Set 赋值并标记为 Changed，这样 MarkFlagRequired 的参数
+// 也可以由它们提供；真正的来源记录在 annotation 中，需要区分时用 flagSource 而不是 Changed
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if err := cfg.validate(cmd.Root()); err != nil {
+		return err
+	}
+
+	path := commandPath(cmd)
+	var applyErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if applyErr != nil || f.Name == "help" || f.Name == "config" {
+			return
+		}
+		r, err := resolveFlag(cfg, path, f)
+		if err != nil {
+			applyErr = err
+			return
+		}
+		if !f.Changed && r.Source != sourceDefault {
+			for _, v := range r.Values {
+				if err := cmd.Flags().Set(f.Name, v); err != nil {
+					applyErr = fmt.Errorf("%s: --%s 的值 %q 不正确: %w", r.Source, f.Name, v, err)
+					return
+				}
+			}
+		}
+		if f.Annotations == nil {
+			f.Annotations = make(map[string][]string)
+		}
+		f.Annotations[sourceAnnotation] = []string{r.Source}
+	})
+	return applyErr
+}
+
+// flagSource 参数值的来源，applyConfig 之前或参数不存在时返回默认值
+func flagSource(cmd *cobra.Command, name string) string {
+	f := cmd.Flags().Lookup(name)
+	if f == nil || len(f.Annotations[sourceAnnotation]) == 0 {
+		return sourceDefault
+	}
+	return f.Annotations[sourceAnnotation][0]
+}
+
+// newConfigCmd 查看配置
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "配置管理",
+	}
+
+	viewCmd := &cobra.Command{
+		Use:   "view [COMMAND...]",
+		Short: "查看各命令参数的最终取值及来源",
+		Long: `显示每个参数的最终取值及来源（命令行参数 > 环境变量 > 配置文件 > 默认值）。
+可以指定命令只查看该命令，如 sysctl config view user list。`,
+		Example: `  sysctl config view
+  SYSCTL_USER_LIST_OUTPUT=json sysctl --config ./sysctl.toml config view user list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			target, rest, err := root.Find(args)
+			if err != nil || len(rest) > 0 {
+				return fmt.Errorf("未知命令：%s", strings.Join(args, " "))
+			}
+			cmd.SilenceUsage = true
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if err := cfg.validate(root); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if cfg.Path != "" {
+				fmt.Fprintf(out, "配置文件: %s\n", cfg.Path)
+			} else {
+				fmt.Fprintln(out, "配置文件: （无）")
+			}
+
+			t := table.New("命令", "参数", "值", "来源")
+			var walk func(c *cobra.Command) error
+			walk = func(c *cobra.Command) error {
+				// help、completion 命令没有需要配置的参数
+				if c.Parent() == root && (c.Name() == "help" || c.Name() == "completion") {
+					return nil
+				}
+				if c.Runnable() && !c.Hidden && c != cmd {
+					if err := viewFlags(t, cfg, c); err != nil {
+						return err
+					}
+				}
+				for _, sub := range c.Commands() {
+					if err := walk(sub); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if err := walk(target); err != nil {
+				return err
+			}
+			return t.Render(out)
+		},
+	}
+
+	configCmd.AddCommand(viewCmd)
+	return configCmd
+}
+
+// viewFlags 把命令的每个参数（含继承的全局参数）加入表格
+func viewFlags(t *table.Table, cfg *configFile, c *cobra.Command) error {
+	path := commandPath(c)
+	var flags []*pflag.Flag
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) { flags = append(flags, f) })
+	c.InheritedFlags().VisitAll(func(f *pflag.Flag) { flags = append(flags, f) })
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+	for _, f := range flags {
+		if f.Name == "help" || f.Name == "config" {
+			continue
+		}
+		r, err := resolveFlag(cfg, path, f)
+		if err != nil {
+			return err
+		}
+		t.Row(c.CommandPath(), "--"+f.Name, strings.Join(r.Values, ", "), r.Source)
+	}
+	return nil
+}
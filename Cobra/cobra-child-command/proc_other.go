@@ -0,0 +1,40 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+func detachAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// processAlive 非 Unix 平台通过 FindProcess 判断进程是否存在
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// processStartTime 非 Unix 平台无法取得进程启动时间，只能按 pid 判断
+func processStartTime(pid int) string {
+	return ""
+}
+
+// terminateProcess 非 Unix 平台没有 SIGTERM，直接结束进程
+func terminateProcess(pid int) error {
+	return killProcess(pid)
+}
+
+func killProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
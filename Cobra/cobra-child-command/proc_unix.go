@@ -0,0 +1,74 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// detachAttr 服务进程新建会话：脱离终端，并成为进程组组长，停止时可以连同子进程一起结束
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive 用 0 号信号探测进程是否存在；EPERM 表示进程存在但属于其他用户。
+// 服务进程的父进程是 init，容器中的 init 不一定及时回收子进程，僵尸进程也视为已退出
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	if err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	return !isZombie(pid)
+}
+
+// isZombie 判断进程是否为僵尸进程；没有 /proc 的系统返回 false
+func isZombie(pid int) bool {
+	f := procStat(pid)
+	return len(f) > 0 && f[0] == "Z"
+}
+
+// processStartTime 进程的启动时间（/proc/<pid>/stat 第 22 个字段，开机以来的时钟周期数），
+// 与 pid 一起唯一标识一个进程，避免 pid 被复用后误认；没有 /proc 的系统返回空串
+func processStartTime(pid int) string {
+	if f := procStat(pid); len(f) > 19 {
+		return f[19]
+	}
+	return ""
+}
+
+// procStat 读取 /proc/<pid>/stat 中 comm 之后的字段（第一个为第 3 个字段 state）。
+// 格式为 "pid (comm) state ..."，comm 中可能有空格和括号，从最后一个 ')' 之后开始切分
+func procStat(pid int) []string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil
+	}
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return nil
+	}
+	return strings.Fields(string(data[i+1:]))
+}
+
+// terminateProcess 向服务所在的进程组发送 SIGTERM
+func terminateProcess(pid int) error {
+	return signalGroup(pid, syscall.SIGTERM)
+}
+
+// killProcess 向服务所在的进程组发送 SIGKILL
+func killProcess(pid int) error {
+	return signalGroup(pid, syscall.SIGKILL)
+}
+
+// signalGroup 服务以新会话启动，pid 即进程组 ID；进程组已不存在时退回到只发给该进程
+func signalGroup(pid int, sig syscall.Signal) error {
+	if err := syscall.Kill(-pid, sig); !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// userFilter 一个过滤条件，形如 role=admin、email~example.com、created>=2024-01-01
+type userFilter struct {
+	Field string
+	Op    string
+	Value string
+	from  time.Time // created 的取值范围 [from, to)
+	to    time.Time
+}
+
+// filterOps 按长度从长到短排列，保证 >= 不会被识别成 >
+var filterOps = []string{"!=", ">=", "<=", "!~", "=", "~", ">", "<"}
+
+// createdLayouts created 支持的写法；只有日期时表示当天整天
+var createdLayouts = []struct {
+	layout string
+	span   time.Duration
+}{
+	{"2006-01-02", 24 * time.Hour},
+	{"2006-01-02 15:04:05", time.Second},
+	{"2006-01-02T15:04:05", time.Second},
+	{time.RFC3339, time.Second},
+}
+
+// parseFilter 解析一个过滤表达式。字段：name、email、role、created；
+// 操作符：= != ~（包含，不区分大小写） !~ > >= < <=
+func parseFilter(expr string) (userFilter, error) {
+	i := strings.IndexAny(expr, "=!~<>")
+	if i <= 0 {
+		return userFilter{}, fmt.Errorf("过滤条件 %q 格式不正确，应为 字段 操作符 值，如 role=admin", expr)
+	}
+	f := userFilter{Field: strings.TrimSpace(expr[:i])}
+	rest := expr[i:]
+	for _, op := range filterOps {
+		if strings.HasPrefix(rest, op) {
+			f.Op, f.Value = op, strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if f.Op == "" {
+		return userFilter{}, fmt.Errorf("过滤条件 %q 的操作符不正确，支持 = != ~ !~ > >= < <=", expr)
+	}
+
+	switch f.Field {
+	case "name", "email", "role":
+	case "created":
+		if f.Op == "~" || f.Op == "!~" {
+			return userFilter{}, fmt.Errorf("created 不支持 %s，请使用 = != > >= < <=", f.Op)
+		}
+		for _, l := range createdLayouts {
+			if t, err := time.ParseInLocation(l.layout, f.Value, time.Local); err == nil {
+				f.from, f.to = t, t.Add(l.span)
+				return f, nil
+			}
+		}
+		return userFilter{}, fmt.Errorf("created 的值 %q 不是有效时间，应为 2006-01-02 或 2006-01-02T15:04:05", f.Value)
+	default:
+		return userFilter{}, fmt.Errorf("不支持按 %q 过滤，可用字段：name、email、role、created", f.Field)
+	}
+	return f, nil
+}
+
+// Match 判断用户是否满足条件
+func (f userFilter) Match(u User) bool {
+	if f.Field == "created" {
+		t := u.CreatedAt
+		switch f.Op {
+		case "=":
+			return !t.Before(f.from) && t.Before(f.to)
+		case "!=":
+			return t.Before(f.from) || !t.Before(f.to)
+		case ">":
+			return !t.Before(f.to)
+		case ">=":
+			return !t.Before(f.from)
+		case "<":
+			return t.Before(f.from)
+		case "<=":
+			return t.Before(f.to)
+		}
+		return false
+	}
+
+	v := userField(u, f.Field)
+	switch f.Op {
+	case "=":
+		return v == f.Value
+	case "!=":
+		return v != f.Value
+	case "~":
+		return strings.Contains(strings.ToLower(v), strings.ToLower(f.Value))
+	case "!~":
+		return !strings.Contains(strings.ToLower(v), strings.ToLower(f.Value))
+	case ">":
+		return v > f.Value
+	case ">=":
+		return v >= f.Value
+	case "<":
+		return v < f.Value
+	case "<=":
+		return v <= f.Value
+	}
+	return false
+}
+
+func userField(u User, field string) string {
+	switch field {
+	case "name":
+		return u.Name
+	case "email":
+		return u.Email
+	case "role":
+		return u.Role
+	}
+	return ""
+}
+
+// sortKey 一个排序字段，Desc 为 true 时降序
+type sortKey struct {
+	Field string
+	Desc  bool
+}
+
+// parseSort 解析 --sort，如 "role,-created"：逗号分隔多个字段，前缀 - 表示降序
+func parseSort(s string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		k := sortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		switch k.Field {
+		case "id", "name", "email", "role", "created":
+		default:
+			return nil, fmt.Errorf("不支持按 %q 排序，可用字段：id、name、email、role、created", part)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// compareUsers 按排序字段依次比较，全部相同时按 ID 升序，保证分页结果稳定
+func compareUsers(keys []sortKey) func(a, b User) int {
+	return func(a, b User) int {
+		for _, k := range keys {
+			var c int
+			switch k.Field {
+			case "id":
+				c = cmp.Compare(a.ID, b.ID)
+			case "created":
+				c = a.CreatedAt.Compare(b.CreatedAt)
+			default:
+				c = strings.Compare(userField(a, k.Field), userField(b, k.Field))
+			}
+			if k.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.ID, b.ID)
+	}
+}
+
+// userQuery user list 的查询条件：过滤（条件之间为“且”）、排序、分页
+type userQuery struct {
+	Filters []userFilter
+	Sort    []sortKey
+	Limit   int // 0 表示不限制
+	Offset  int
+}
+
+// Apply 返回当前页的用户，以及分页前满足条件的总数
+func (q userQuery) Apply(users []User) ([]User, int) {
+	matched := slices.DeleteFunc(slices.Clone(users), func(u User) bool {
+		for _, f := range q.Filters {
+			if !f.Match(u) {
+				return true
+			}
+		}
+		return false
+	})
+	slices.SortStableFunc(matched, compareUsers(q.Sort))
+
+	total := len(matched)
+	page := matched[min(q.Offset, total):]
+	if q.Limit > 0 && q.Limit < len(page) {
+		page = page[:q.Limit]
+	}
+	return page, total
+}
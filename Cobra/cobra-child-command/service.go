@@ -0,0 +1,528 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// serviceDef 服务定义，每个服务一个文件：<services-dir>/<name>.yaml（也可以是 .yml 或 .json）
+//
+//	command: /usr/bin/python3
+//	args: ["-m", "http.server", "8000"]
+//	env:
+//	  PYTHONUNBUFFERED: "1"
+//	workdir: /srv/www        # 相对路径相对于定义文件所在目录
+//	stop_timeout: 15s        # 可选，覆盖 stop 的 --timeout 默认值
+type serviceDef struct {
+	Name        string            `yaml:"-"`
+	Command     string            `yaml:"command"`
+	Args        []string          `yaml:"args"`
+	Env         map[string]string `yaml:"env"`
+	WorkDir     string            `yaml:"workdir"`
+	StopTimeout time.Duration     `yaml:"stop_timeout"`
+}
+
+// serviceExts 服务定义文件的扩展名，按查找顺序排列
+var serviceExts = []string{".yaml", ".yml", ".json"}
+
+var serviceNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// defaultStopTimeout SIGTERM 之后等待进程退出的默认时间，超时发送 SIGKILL
+const defaultStopTimeout = 10 * time.Second
+
+// serviceDirs 服务定义目录和运行状态目录（pid 文件、日志）
+type serviceDirs struct {
+	Services string
+	State    string
+}
+
+func (d serviceDirs) pidFile(name string) string {
+	return filepath.Join(d.State, "run", name+".pid")
+}
+
+func (d serviceDirs) logFiles(name string) (stdout, stderr string) {
+	dir := filepath.Join(d.State, "log")
+	return filepath.Join(dir, name+".out.log"), filepath.Join(dir, name+".err.log")
+}
+
+// defaultServicesDir $XDG_CONFIG_HOME/sysctl/services 或 ~/.config/sysctl/services
+func defaultServicesDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "sysctl", "services")
+}
+
+// defaultStateDir $XDG_STATE_HOME/sysctl 或 ~/.local/state/sysctl
+func defaultStateDir() string {
+	if d := os.Getenv("XDG_STATE_HOME"); d != "" {
+		return filepath.Join(d, "sysctl")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "sysctl")
+	}
+	return filepath.Join(os.TempDir(), "sysctl")
+}
+
+func getServiceDirs(cmd *cobra.Command) serviceDirs {
+	services, _ := cmd.Flags().GetString("services-dir")
+	state, _ := cmd.Flags().GetString("state-dir")
+	return serviceDirs{Services: services, State: state}
+}
+
+// loadService 读取服务定义
+func loadService(dir, name string) (serviceDef, error) {
+	if !serviceNameRE.MatchString(name) {
+		return serviceDef{}, fmt.Errorf("服务名 %q 不合法，只能包含字母、数字、.、_、-", name)
+	}
+	for _, ext := range serviceExts {
+		path := filepath.Join(dir, name+ext)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return serviceDef{}, err
+		}
+		return parseService(path, name, data)
+	}
+	return serviceDef{}, fmt.Errorf("服务 %q 未定义（在 %s 中找不到 %s.yaml）", name, dir, name)
+}
+
+func parseService(path, name string, data []byte) (serviceDef, error) {
+	def := serviceDef{Name: name}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		return def, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	if def.Command == "" {
+		return def, fmt.Errorf("%s: 缺少 command", path)
+	}
+	if def.StopTimeout < 0 {
+		return def, fmt.Errorf("%s: stop_timeout 不能为负数", path)
+	}
+	if def.WorkDir != "" && !filepath.IsAbs(def.WorkDir) {
+		def.WorkDir = filepath.Join(filepath.Dir(path), def.WorkDir)
+	}
+	return def, nil
+}
+
+// listServices 返回定义目录中所有服务的名称（已排序）；同名的多个文件只算一个
+func listServices(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		name := strings.TrimSuffix(e.Name(), ext)
+		if e.IsDir() || !isServiceExt(ext) || !serviceNameRE.MatchString(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func isServiceExt(ext string) bool {
+	for _, e := range serviceExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceState 服务的运行状态
+type serviceState struct {
+	Running bool
+	Pid     int
+	Ident   string    // 进程启动时间，与 pid 一起标识服务进程（见 processStartTime）
+	Since   time.Time // pid 文件的修改时间，即启动时间
+	Stale   bool      // pid 文件存在但进程已不存在，或 pid 已被其他进程复用
+}
+
+// readState 根据 pid 文件判断服务是否在运行。pid 文件第一行为 pid，第二行为进程启动时间
+func readState(d serviceDirs, name string) (serviceState, error) {
+	path := d.pidFile(name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return serviceState{}, nil
+	}
+	if err != nil {
+		return serviceState{}, err
+	}
+	pidLine, ident, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(pidLine))
+	if err != nil || pid <= 0 {
+		return serviceState{}, fmt.Errorf("pid 文件 %s 内容不正确", path)
+	}
+	st := serviceState{Pid: pid, Ident: strings.TrimSpace(ident)}
+	if info, err := os.Stat(path); err == nil {
+		st.Since = info.ModTime()
+	}
+	st.Running = sameProcess(pid, st.Ident)
+	st.Stale = !st.Running
+	return st, nil
+}
+
+// sameProcess pid 对应的进程仍是写 pid 文件时启动的那个：进程存在且启动时间一致。
+// 系统支持启动时间而 pid 文件中没有记录时无法确认，按已退出处理，宁可不停止也不能误杀其他进程；
+// 不支持时（两边都为空）只能按 pid 判断
+func sameProcess(pid int, ident string) bool {
+	return processAlive(pid) && processStartTime(pid) == ident
+}
+
+// lockService 对服务加排他锁，避免多个 sysctl 同时启动或停止同一个服务
+func lockService(d serviceDirs, name string) (unlock func(), err error) {
+	dir := filepath.Dir(d.pidFile(name))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, name+".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, true); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// startService 在后台启动服务：新建会话脱离终端，stdout、stderr 追加到日志文件，然后写 pid 文件。
+// 进程在短时间内退出时视为启动失败
+func startService(d serviceDirs, def serviceDef) (int, error) {
+	unlock, err := lockService(d, def.Name)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	st, err := readState(d, def.Name)
+	if err != nil {
+		return 0, err
+	}
+	if st.Running {
+		return 0, fmt.Errorf("服务 %s 已在运行（pid %d）", def.Name, st.Pid)
+	}
+
+	outPath, errPath := d.logFiles(def.Name)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return 0, err
+	}
+	stdout, err := os.OpenFile(outPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer stdout.Close()
+	stderr, err := os.OpenFile(errPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer stderr.Close()
+
+	c := exec.Command(def.Command, def.Args...)
+	c.Dir = def.WorkDir
+	c.Env = os.Environ()
+	keys := make([]string, 0, len(def.Env))
+	for k := range def.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c.Env = append(c.Env, k+"="+def.Env[k])
+	}
+	c.Stdout, c.Stderr = stdout, stderr
+	c.SysProcAttr = detachAttr()
+
+	fmt.Fprintf(stdout, "=== %s 启动 %s ===\n", time.Now().Format("2006-01-02 15:04:05"), def.Name)
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("启动 %s 失败: %w", def.Name, err)
+	}
+	pid := c.Process.Pid
+
+	exited := make(chan error, 1)
+	go func() { exited <- c.Wait() }()
+	select {
+	case err := <-exited:
+		return 0, fmt.Errorf("服务 %s 启动后立即退出（%v），请查看日志 %s", def.Name, exitStatus(err), errPath)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	pidData := fmt.Sprintf("%d\n%s\n", pid, processStartTime(pid))
+	if err := os.WriteFile(d.pidFile(def.Name), []byte(pidData), 0o644); err != nil {
+		return pid, fmt.Errorf("服务已启动（pid %d），但写入 pid 文件失败: %w", pid, err)
+	}
+	return pid, nil
+}
+
+func exitStatus(err error) string {
+	if err == nil {
+		return "退出码 0"
+	}
+	return err.Error()
+}
+
+// stopService 发送 SIGTERM，超时后发送 SIGKILL；服务未运行时返回 false
+func stopService(d serviceDirs, name string, timeout time.Duration) (stopped bool, killed bool, err error) {
+	unlock, err := lockService(d, name)
+	if err != nil {
+		return false, false, err
+	}
+	defer unlock()
+
+	st, err := readState(d, name)
+	if err != nil {
+		return false, false, err
+	}
+	if !st.Running {
+		// 清理残留的 pid 文件
+		if st.Stale {
+			os.Remove(d.pidFile(name))
+		}
+		return false, false, nil
+	}
+
+	if err := terminateProcess(st.Pid); err != nil {
+		return false, false, fmt.Errorf("向 pid %d 发送 SIGTERM 失败: %w", st.Pid, err)
+	}
+	if !waitExit(st.Pid, st.Ident, timeout) {
+		if err := killProcess(st.Pid); err != nil {
+			return false, false, fmt.Errorf("向 pid %d 发送 SIGKILL 失败: %w", st.Pid, err)
+		}
+		killed = true
+		if !waitExit(st.Pid, st.Ident, 5*time.Second) {
+			return false, true, fmt.Errorf("发送 SIGKILL 后 pid %d 仍未退出", st.Pid)
+		}
+	}
+	if err := os.Remove(d.pidFile(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return true, killed, err
+	}
+	return true, killed, nil
+}
+
+// waitExit 轮询直到进程退出或超时
+func waitExit(pid int, ident string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for sameProcess(pid, ident) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// stopTimeout --timeout 由命令行、环境变量或配置文件指定时优先，否则使用服务定义中的 stop_timeout
+func stopTimeout(cmd *cobra.Command, def serviceDef) time.Duration {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if flagSource(cmd, "timeout") == sourceDefault && def.StopTimeout > 0 {
+		return def.StopTimeout
+	}
+	return timeout
+}
+
+// newServiceCmd 服务管理：启动、停止、重启、查看状态
+func newServiceCmd() *cobra.Command {
+	serviceCmd := &cobra.Command{
+		Use:   "service",
+		Short: "服务管理",
+		Long: `管理服务定义目录中定义的本地进程。每个服务一个 YAML 文件（<名称>.yaml），
+包含 command、args、env、workdir 和可选的 stop_timeout。
+进程的 pid 文件和 stdout、stderr 日志保存在状态目录中。`,
+	}
+	serviceCmd.PersistentFlags().String("services-dir", defaultServicesDir(), "服务定义目录")
+	serviceCmd.PersistentFlags().String("state-dir", defaultStateDir(), "状态目录（pid 文件和日志）")
+
+	// 启动服务子命令
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "启动服务",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			name, _ := cmd.Flags().GetString("name")
+			d := getServiceDirs(cmd)
+			def, err := loadService(d.Services, name)
+			if err != nil {
+				return err
+			}
+			pid, err := startService(d, def)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "启动服务: %s（pid %d）\n", name, pid)
+			return nil
+		},
+	}
+	startCmd.Flags().StringP("name", "n", "", "服务名称")
+	startCmd.MarkFlagRequired("name")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "停止服务（先 SIGTERM，超时后 SIGKILL）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			name, _ := cmd.Flags().GetString("name")
+			d := getServiceDirs(cmd)
+			def, err := loadService(d.Services, name)
+			if err != nil {
+				return err
+			}
+			return runStop(cmd, d, def)
+		},
+	}
+	stopCmd.Flags().StringP("name", "n", "", "服务名称")
+	stopCmd.Flags().Duration("timeout", defaultStopTimeout, "等待进程退出的时间，超时发送 SIGKILL")
+	stopCmd.MarkFlagRequired("name")
+
+	restartCmd := &cobra.Command{
+		Use:   "restart",
+		Short: "重启服务（未运行时直接启动）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			name, _ := cmd.Flags().GetString("name")
+			d := getServiceDirs(cmd)
+			def, err := loadService(d.Services, name)
+			if err != nil {
+				return err
+			}
+			if err := runStop(cmd, d, def); err != nil {
+				return err
+			}
+			pid, err := startService(d, def)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "启动服务: %s（pid %d）\n", name, pid)
+			return nil
+		},
+	}
+	restartCmd.Flags().StringP("name", "n", "", "服务名称")
+	restartCmd.Flags().Duration("timeout", defaultStopTimeout, "等待进程退出的时间，超时发送 SIGKILL")
+	restartCmd.MarkFlagRequired("name")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "查看服务运行状态（不指定 --name 时显示全部）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			d := getServiceDirs(cmd)
+			names, err := listServices(d.Services)
+			if err != nil {
+				return err
+			}
+			if name, _ := cmd.Flags().GetString("name"); name != "" {
+				if _, err := loadService(d.Services, name); err != nil {
+					return err
+				}
+				names = []string{name}
+			}
+
+			t := table.New("服务", "状态", "PID", "启动时间", "日志")
+			for _, name := range names {
+				st, err := readState(d, name)
+				if err != nil {
+					t.Row(name, "错误: "+err.Error())
+					continue
+				}
+				outLog, _ := d.logFiles(name)
+				switch {
+				case st.Running:
+					t.Row(name, "运行中", strconv.Itoa(st.Pid), st.Since.Format("2006-01-02 15:04:05"), outLog)
+				case st.Stale:
+					t.Row(name, "已退出", strconv.Itoa(st.Pid), "", outLog)
+				default:
+					t.Row(name, "未运行")
+				}
+			}
+			return t.Render(cmd.OutOrStdout())
+		},
+	}
+	statusCmd.Flags().StringP("name", "n", "", "服务名称")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出已定义的服务",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			d := getServiceDirs(cmd)
+			names, err := listServices(d.Services)
+			if err != nil {
+				return err
+			}
+			t := table.New("服务", "命令", "工作目录")
+			for _, name := range names {
+				def, err := loadService(d.Services, name)
+				if err != nil {
+					t.Row(name, "错误: "+strings.ReplaceAll(err.Error(), "\n", " "))
+					continue
+				}
+				t.Row(name, strings.Join(append([]string{def.Command}, def.Args...), " "), def.WorkDir)
+			}
+			if err := t.Render(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "（%s 中没有服务定义）\n", d.Services)
+			}
+			return nil
+		},
+	}
+
+	// --name 按 Tab 时补全已定义的服务名
+	for _, c := range []*cobra.Command{startCmd, stopCmd, restartCmd, statusCmd} {
+		c.RegisterFlagCompletionFunc("name", completeServiceNames)
+	}
+
+	serviceCmd.AddCommand(startCmd, stopCmd, restartCmd, statusCmd, listCmd)
+	return serviceCmd
+}
+
+func runStop(cmd *cobra.Command, d serviceDirs, def serviceDef) error {
+	stopped, killed, err := stopService(d, def.Name, stopTimeout(cmd, def))
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	switch {
+	case !stopped:
+		fmt.Fprintf(out, "服务 %s 未运行\n", def.Name)
+	case killed:
+		fmt.Fprintf(out, "停止服务: %s（超时未退出，已发送 SIGKILL）\n", def.Name)
+	default:
+		fmt.Fprintf(out, "停止服务: %s\n", def.Name)
+	}
+	return nil
+}
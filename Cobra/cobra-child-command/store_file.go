@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileData JSON 文件的内容
+type fileData struct {
+	NextID uint64 `json:"next_id"`
+	Users  []User `json:"users"`
+}
+
+// fileStore 把用户保存在一个 JSON 文件中。每次操作都在同目录的 .lock 文件上加锁后
+// 完整读取、修改并原子替换数据文件，多个进程同时执行时互不覆盖
+type fileStore struct {
+	path string
+}
+
+func newFileStore(path string) (*fileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &fileStore{path: path}, nil
+}
+
+func (s *fileStore) List(ctx context.Context) ([]User, error) {
+	var users []User
+	err := s.withLock(false, func(d *fileData) (bool, error) {
+		users = d.Users
+		return false, nil
+	})
+	return users, err
+}
+
+func (s *fileStore) Add(ctx context.Context, u User) (User, error) {
+	if err := validateUser(u); err != nil {
+		return User{}, err
+	}
+	err := s.withLock(true, func(d *fileData) (bool, error) {
+		for _, e := range d.Users {
+			if e.Name == u.Name {
+				return false, ErrUserExists
+			}
+		}
+		d.NextID++
+		u.ID = d.NextID
+		u.CreatedAt = time.Now().Truncate(time.Second)
+		d.Users = append(d.Users, u)
+		return true, nil
+	})
+	return u, err
+}
+
+func (s *fileStore) Delete(ctx context.Context, name string) error {
+	return s.withLock(true, func(d *fileData) (bool, error) {
+		for i, e := range d.Users {
+			if e.Name == name {
+				d.Users = append(d.Users[:i], d.Users[i+1:]...)
+				return true, nil
+			}
+		}
+		return false, ErrUserNotFound
+	})
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
+
+// withLock 加锁后读取数据并调用 fn；fn 返回 true 时写回。只读操作使用共享锁
+func (s *fileStore) withLock(write bool, fn func(d *fileData) (bool, error)) error {
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock, write); err != nil {
+		return fmt.Errorf("锁定 %s 失败: %w", s.path, err)
+	}
+	defer unlockFile(lock)
+
+	d, err := s.read()
+	if err != nil {
+		return err
+	}
+	changed, err := fn(d)
+	if err != nil || !changed {
+		return err
+	}
+	return s.write(d)
+}
+
+// read 读取数据文件，文件不存在时视为没有用户
+func (s *fileStore) read() (*fileData, error) {
+	d := &fileData{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", s.path, err)
+	}
+	return d, nil
+}
+
+// write 先写临时文件再重命名，中途失败不会留下半个文件
+func (s *fileStore) write(d *fileData) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".users-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlStore 使用 GoUseMySQL/OperateTable 中的 users 表，并在缺少时补上 role 列
+type mysqlStore struct {
+	db *sql.DB
+}
+
+// createUsersTable 与 GoUseMySQL/OperateTable 的表结构一致，另加 role 列
+const createUsersTable = `
+	CREATE TABLE IF NOT EXISTS users (
+		user_id INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键',
+		username VARCHAR(50) NOT NULL COMMENT '用户名',
+		email VARCHAR(100) NOT NULL COMMENT '邮箱地址',
+		role VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT '角色',
+		create_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+`
+
+func newMySQLStore(ctx context.Context, dsn string) (*mysqlStore, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("MySQL DSN 不正确: %w", err)
+	}
+	// create_at 需要解析为 time.Time，并按本地时区解释
+	cfg.ParseTime = true
+	cfg.Loc = time.Local
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("连接 MySQL 失败: %w", err)
+	}
+	s := &mysqlStore{db: db}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate 建表；已有的 users 表（如 OperateTable 创建的）没有 role 列时补上
+func (s *mysqlStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, createUsersTable); err != nil {
+		return fmt.Errorf("创建 users 表失败: %w", err)
+	}
+	var n int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role'`).Scan(&n)
+	if err != nil {
+		return fmt.Errorf("检查 users 表结构失败: %w", err)
+	}
+	if n == 0 {
+		_, err = s.db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT '角色' AFTER email")
+		if err != nil {
+			return fmt.Errorf("添加 role 列失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *mysqlStore) List(ctx context.Context) ([]User, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT user_id, username, email, role, create_at FROM users ORDER BY user_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		var created sql.NullTime
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &created); err != nil {
+			return nil, err
+		}
+		u.CreatedAt = created.Time
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Add 表上没有唯一索引，用 INSERT ... SELECT ... WHERE NOT EXISTS 在一条语句内检查重名
+func (s *mysqlStore) Add(ctx context.Context, u User) (User, error) {
+	if err := validateUser(u); err != nil {
+		return User{}, err
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO users (username, email, role)
+		SELECT ?, ?, ? FROM DUAL
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE username = ?)`,
+		u.Name, u.Email, u.Role, u.Name)
+	if err != nil {
+		return User{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return User{}, err
+	} else if n == 0 {
+		return User{}, ErrUserExists
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return User{}, err
+	}
+
+	var created sql.NullTime
+	if err := s.db.QueryRowContext(ctx, "SELECT create_at FROM users WHERE user_id = ?", id).Scan(&created); err != nil {
+		return User{}, err
+	}
+	u.ID = uint64(id)
+	u.CreatedAt = created.Time
+	return u, nil
+}
+
+func (s *mysqlStore) Delete(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE username = ?", name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *mysqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// userStore 打开 --store 指定的用户存储。参数已通过校验，之后的错误不再打印用法
+func userStore(cmd *cobra.Command) (UserStore, error) {
+	cmd.SilenceUsage = true
+	url, _ := cmd.Flags().GetString("store")
+	if debug, _ := cmd.Flags().GetBool("debug"); debug {
+		fmt.Fprintf(cmd.ErrOrStderr(), "[debug] 用户存储：%s\n", url)
+	}
+	return openStore(cmd.Context(), url)
+}
+
+func runUserAdd(cmd *cobra.Command, u User) error {
+	store, err := userStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	added, err := store.Add(cmd.Context(), u)
+	if err != nil {
+		return fmt.Errorf("添加用户 %s 失败: %w", u.Name, err)
+	}
+	u = added
+	fmt.Fprintf(cmd.OutOrStdout(), "添加用户：%s\n", u.Name)
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		fmt.Fprintf(cmd.OutOrStdout(), "ID: %d\n邮箱: %s\n角色: %s\n创建时间: %s\n",
+			u.ID, u.Email, u.Role, u.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// readNames 读取 --from-file：每行一个用户名，忽略空行和 # 开头的注释
+func readNames(r io.Reader) ([]string, error) {
+	var names []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			names = append(names, line)
+		}
+	}
+	return names, sc.Err()
+}
+
+// deleteTargets 合并参数和 --from-file 中的用户名，去掉重复的并保持顺序
+func deleteTargets(cmd *cobra.Command, args []string, fromFile string) ([]string, error) {
+	names := slices.Clone(args)
+	if fromFile != "" {
+		var r io.Reader = cmd.InOrStdin()
+		if fromFile != "-" {
+			f, err := os.Open(fromFile)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		more, err := readNames(r)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %w", fromFile, err)
+		}
+		names = append(names, more...)
+	}
+
+	seen := make(map[string]bool)
+	return slices.DeleteFunc(names, func(n string) bool {
+		dup := seen[n]
+		seen[n] = true
+		return dup
+	}), nil
+}
+
+// confirm 在标准输入上询问，只有输入 y 或 yes 才继续
+func confirm(cmd *cobra.Command, prompt string) (bool, error) {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// runUserDelete 逐个删除并报告每个用户的结果，有任何失败时返回错误（退出码非 0）
+func runUserDelete(cmd *cobra.Command, args []string, fromFile string, yes bool) error {
+	cmd.SilenceUsage = true
+	names, err := deleteTargets(cmd, args, fromFile)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%s 中没有用户名", fromFile)
+	}
+
+	out := cmd.OutOrStdout()
+	if !yes {
+		if fromFile == "-" {
+			return fmt.Errorf("从标准输入读取用户名时无法交互确认，请加 --yes")
+		}
+		fmt.Fprintf(out, "将删除以下 %d 个用户：\n", len(names))
+		for _, n := range names {
+			fmt.Fprintf(out, "- %s\n", n)
+		}
+		ok, err := confirm(cmd, "确认删除？")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("已取消，未删除任何用户")
+		}
+	}
+
+	store, err := userStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	done, failed := 0, 0
+	for _, name := range names {
+		if err := store.Delete(cmd.Context(), name); err != nil {
+			failed++
+			fmt.Fprintf(cmd.ErrOrStderr(), "删除用户 %s 失败: %v\n", name, err)
+			continue
+		}
+		done++
+		fmt.Fprintf(out, "删除用户: %s\n", name)
+	}
+	if len(names) > 1 {
+		fmt.Fprintf(out, "----\n成功: %d\n失败: %d\n", done, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个用户删除失败", failed)
+	}
+	return nil
+}
+
+// parseUserQuery 解析 user list 的过滤、排序和分页参数
+func parseUserQuery(cmd *cobra.Command) (userQuery, error) {
+	var q userQuery
+	exprs, _ := cmd.Flags().GetStringArray("filter")
+	for _, e := range exprs {
+		f, err := parseFilter(e)
+		if err != nil {
+			return q, err
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	sortBy, _ := cmd.Flags().GetString("sort")
+	keys, err := parseSort(sortBy)
+	if err != nil {
+		return q, err
+	}
+	q.Sort = keys
+	q.Limit, _ = cmd.Flags().GetInt("limit")
+	q.Offset, _ = cmd.Flags().GetInt("offset")
+	if q.Limit < 0 || q.Offset < 0 {
+		return q, fmt.Errorf("--limit 和 --offset 不能为负数")
+	}
+	return q, nil
+}
+
+func runUserList(cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	if output != "table" && output != "json" && output != "yaml" {
+		return fmt.Errorf("--output 必须是 table、json 或 yaml，当前为 %q", output)
+	}
+	q, err := parseUserQuery(cmd)
+	if err != nil {
+		return err
+	}
+
+	store, err := userStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	users, err := store.List(cmd.Context())
+	if err != nil {
+		return err
+	}
+	page, total := q.Apply(users)
+	if page == nil {
+		page = []User{}
+	}
+
+	out := cmd.OutOrStdout()
+	switch output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(page)
+	case "yaml":
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(page); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	t := table.New("ID", "用户名", "邮箱", "角色", "创建时间").AlignRight(0)
+	for _, u := range page {
+		t.Row(strconv.FormatUint(u.ID, 10), u.Name, u.Email, u.Role, u.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	if err := t.Render(out); err != nil {
+		return err
+	}
+	switch {
+	case len(page) == 0 && total > 0:
+		fmt.Fprintf(out, "----\n--offset %d 超出范围，共 %d 个用户\n", q.Offset, total)
+	case len(page) < total:
+		fmt.Fprintf(out, "----\n显示第 %d-%d 个，共 %d 个用户\n", q.Offset+1, q.Offset+len(page), total)
+	default:
+		fmt.Fprintf(out, "----\n共 %d 个用户\n", total)
+	}
+	return nil
+}
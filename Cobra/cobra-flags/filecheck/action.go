@@ -0,0 +1,321 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// action 对匹配文件执行的操作
+type action struct {
+	Kind string // delete | move | gzip | trash
+	Dir  string // move 的目标目录
+}
+
+// parseAction 解析 --action：delete、move:<dir>、gzip、trash
+func parseAction(s string) (action, error) {
+	kind, dir, hasDir := strings.Cut(s, ":")
+	switch {
+	case kind == "move" && hasDir && dir != "":
+		return action{Kind: kind, Dir: dir}, nil
+	case kind == "move":
+		return action{}, fmt.Errorf("--action move 需要指定目录，如 move:/data/archive")
+	case !hasDir && (kind == "delete" || kind == "gzip" || kind == "trash"):
+		return action{Kind: kind}, nil
+	}
+	return action{}, fmt.Errorf("--action 必须是 delete、move:<dir>、gzip 或 trash，当前为 %q", s)
+}
+
+// actionNames 操作的中文名称（文本输出使用）
+var actionNames = map[string]string{
+	"delete":  "删除",
+	"move":    "移动",
+	"gzip":    "压缩",
+	"trash":   "移到回收站",
+	"restore": "恢复",
+}
+
+// dataDir filecheck 的数据目录，存放回收站和操作日志：$XDG_DATA_HOME/filecheck 或 ~/.local/share/filecheck
+func dataDir() string {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "filecheck")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "filecheck")
+	}
+	return filepath.Join(os.TempDir(), "filecheck")
+}
+
+// actionRecord 操作日志中的一条记录（每行一个 JSON 对象，只追加）
+type actionRecord struct {
+	Time   time.Time `json:"time"`
+	Run    string    `json:"run"` // 同一次运行的所有记录共享的 ID
+	Action string    `json:"action"`
+	Path   string    `json:"path"`
+	Dest   string    `json:"dest,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// actionLog 追加写入的操作日志
+type actionLog struct {
+	f   *os.File
+	enc *json.Encoder
+	run string
+}
+
+func openActionLog(path, run string) (*actionLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("打开操作日志失败: %w", err)
+	}
+	return &actionLog{f: f, enc: json.NewEncoder(f), run: run}, nil
+}
+
+// Write 记录一次操作，操作失败时同时记录错误
+func (l *actionLog) Write(kind, path, dest string, opErr error) error {
+	rec := actionRecord{Time: time.Now(), Run: l.run, Action: kind, Path: absOrSelf(path), Dest: absOrSelf(dest)}
+	if opErr != nil {
+		rec.Error = opErr.Error()
+	}
+	return l.enc.Encode(rec)
+}
+
+func (l *actionLog) Close() error {
+	return l.f.Close()
+}
+
+// absOrSelf 日志中记录绝对路径，便于事后追查
+func absOrSelf(p string) string {
+	if p == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// newRunID 本次运行的 ID，同时作为回收站子目录名
+func newRunID() string {
+	return fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), os.Getpid())
+}
+
+// actionOptions --action 相关参数
+type actionOptions struct {
+	Action   action
+	Yes      bool   // 为 false 时只预演，不修改任何文件
+	TrashDir string // 回收站根目录，每次运行在其下创建一个子目录
+	LogPath  string // 操作日志路径
+}
+
+// runAction 先完整扫描，再逐个处理匹配的文件（扫描过程中不修改目录树）
+func runAction(cmd *cobra.Command, opts scanOptions, ao actionOptions) error {
+	var files []fileEntry
+	var errs []jsonError
+	summary := scan(cmd.Context(), opts, func(f fileEntry) { files = append(files, f) }, func(p string, err error) {
+		errs = append(errs, jsonError{Path: p, Error: err.Error()})
+	})
+	printErrors(errs)
+	if summary.Canceled {
+		return interrupted(cmd)
+	}
+
+	out := cmd.OutOrStdout()
+	a := ao.Action
+	name := actionNames[a.Kind]
+	if !ao.Yes {
+		for _, f := range files {
+			if dest := actionDest(a, opts.Root, f.Path, "<回收站>"); dest != "" {
+				fmt.Fprintf(out, "[预演] %s %s -> %s\n", name, f.Path, dest)
+			} else {
+				fmt.Fprintf(out, "[预演] %s %s\n", name, f.Path)
+			}
+		}
+		fmt.Fprintf(out, "----\n共 %d 个文件将被%s，未做任何修改，确认无误后加 --yes 执行\n", len(files), name)
+		return nil
+	}
+
+	run := newRunID()
+	log, err := openActionLog(ao.LogPath, run)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	var trash *trashRun
+	if a.Kind == "trash" {
+		if trash, err = newTrashRun(ao.TrashDir, run); err != nil {
+			return err
+		}
+		defer trash.Close()
+	}
+
+	done, failed := 0, 0
+	for _, f := range files {
+		if cmd.Context().Err() != nil {
+			break
+		}
+		var dest string
+		var err error
+		switch a.Kind {
+		case "delete":
+			err = os.Remove(f.Path)
+		case "move":
+			dest = actionDest(a, opts.Root, f.Path, "")
+			err = moveFile(f.Path, dest)
+		case "gzip":
+			dest = f.Path + ".gz"
+			err = gzipFile(f.Path, dest)
+		case "trash":
+			dest, err = trash.Put(opts.Root, f)
+		}
+
+		if logErr := log.Write(a.Kind, f.Path, dest, err); logErr != nil {
+			return fmt.Errorf("写入操作日志失败，已停止: %w", logErr)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s %s 失败: %v\n", name, f.Path, err)
+			continue
+		}
+		done++
+		if dest != "" {
+			fmt.Fprintf(out, "已%s %s -> %s\n", name, f.Path, dest)
+		} else {
+			fmt.Fprintf(out, "已%s %s\n", name, f.Path)
+		}
+	}
+
+	fmt.Fprintln(out, "----")
+	fmt.Fprintf(out, "成功: %d\n", done)
+	fmt.Fprintf(out, "失败: %d\n", failed)
+	if trash != nil {
+		fmt.Fprintf(out, "回收站: %s（用 filecheck restore %s 恢复）\n", trash.dir, trash.dir)
+	}
+	fmt.Fprintf(out, "操作日志: %s\n", ao.LogPath)
+
+	if cmd.Context().Err() != nil {
+		return interrupted(cmd)
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d 个文件处理失败", failed)
+	}
+	return nil
+}
+
+// actionDest 目标路径：move 保留相对扫描根目录的层级，gzip 追加 .gz；trash 的位置在执行时才确定，用 trashHint 表示
+func actionDest(a action, root, path, trashHint string) string {
+	switch a.Kind {
+	case "move":
+		return filepath.Join(a.Dir, filepath.FromSlash(relPath(root, path)))
+	case "gzip":
+		return path + ".gz"
+	case "trash":
+		return trashHint
+	}
+	return ""
+}
+
+// moveFile 移动文件，目标已存在时拒绝覆盖；跨文件系统时退回到复制后删除
+func moveFile(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("目标 %s 已存在", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile 复制文件内容并保留权限和修改时间
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// gzipFile 压缩为 dst 后删除原文件，保留权限和修改时间；失败时删除不完整的 dst
+func gzipFile(src, dst string) (err error) {
+	if strings.EqualFold(filepath.Ext(src), ".gz") {
+		return fmt.Errorf("已经是 gzip 文件")
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(src)
+	zw.ModTime = info.ModTime()
+	if _, err = io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
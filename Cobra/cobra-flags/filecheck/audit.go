@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+	"github.com/spf13/cobra"
+)
+
+// severity 审计发现的严重程度，数值越大越严重
+type severity int
+
+const (
+	severityLow severity = iota + 1
+	severityMedium
+	severityHigh
+	severityCritical
+)
+
+var severityNames = map[severity]string{
+	severityLow:      "low",
+	severityMedium:   "medium",
+	severityHigh:     "high",
+	severityCritical: "critical",
+}
+
+func (s severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalText JSON 中输出为名称而不是数字
+func (s severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// parseFailOn 解析 --fail-on：none 表示不因发现的问题失败（返回 0），有路径无法读取时仍然失败
+func parseFailOn(s string) (severity, error) {
+	s = strings.ToLower(s)
+	if s == "none" {
+		return 0, nil
+	}
+	for sev, name := range severityNames {
+		if name == s {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("--fail-on 必须是 low、medium、high、critical 或 none，当前为 %q", s)
+}
+
+// finding 一条审计发现
+type finding struct {
+	Severity severity `json:"severity"`
+	Check    string   `json:"check"` // 检查项，如 world-writable、setuid
+	Path     string   `json:"path"`
+	Mode     string   `json:"mode"`
+	Detail   string   `json:"detail"`
+}
+
+// auditOptions audit 子命令参数
+type auditOptions struct {
+	Root   string
+	Filter *pathmatch.Filter
+	Owners map[uint32]bool // 允许的属主 UID，为空时不检查属主
+}
+
+// sensitiveNames 私钥、凭据等文件，不应被同组或其他用户读取
+var sensitiveNames = []string{"*.pem", "*.key", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", ".env", ".env.*", "*.p12", "*.pfx", ".netrc", ".pgpass"}
+
+func isSensitive(name string) bool {
+	for _, p := range sensitiveNames {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newAuditCmd 检查权限和属主的安全问题，可作为部署脚本中的门禁
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "安全审计：检查危险的权限、属主和悬空链接",
+		Long: `递归检查路径下的文件和目录，报告以下问题：
+  world-writable  其他用户可写的文件或目录（目录带粘滞位时降为 low）
+  setuid/setgid   设置了 setuid 或 setgid 位的文件（属主为 root 的 setuid 为 critical）
+  owner           属主不在 --allow-owner 列表中的文件
+  dangling-link   指向不存在目标的符号链接
+  permissive      同组可写的文件，或同组/其他用户可读的私钥、凭据文件
+
+发现 --fail-on 级别及以上的问题时以非零状态退出。有路径无法读取时审计不完整，
+即使 --fail-on none 也以非零状态退出。`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // 审计失败不是用法错误，不输出帮助
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, _ := cmd.Flags().GetString("path")
+			exclude, _ := cmd.Flags().GetStringArray("exclude")
+			allowOwners, _ := cmd.Flags().GetStringSlice("allow-owner")
+			failOnFlag, _ := cmd.Flags().GetString("fail-on")
+			output, _ := cmd.Flags().GetString("output")
+
+			failOn, err := parseFailOn(failOnFlag)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("audit 只支持 text 或 json 输出，当前为 %q", output)
+			}
+			filter, err := pathmatch.NewFilter(nil, exclude, "")
+			if err != nil {
+				return err
+			}
+			owners, err := lookupOwners(allowOwners)
+			if err != nil {
+				return err
+			}
+
+			findings, errs := audit(cmd.Context(), auditOptions{Root: root, Filter: filter, Owners: owners})
+			if err := writeFindings(output, cmd.OutOrStdout(), findings, errs); err != nil {
+				return err
+			}
+			if cmd.Context().Err() != nil {
+				return errInterrupted // 不完整的审计不能作为通过
+			}
+			// 根路径不存在或有目录无法读取时，审计同样不完整，无论 --fail-on 如何都不能通过
+			if len(errs) > 0 {
+				return fmt.Errorf("%d 个路径无法读取，审计不完整", len(errs))
+			}
+
+			if failOn == 0 {
+				return nil
+			}
+			n := 0
+			for _, f := range findings {
+				if f.Severity >= failOn {
+					n++
+				}
+			}
+			if n > 0 {
+				return fmt.Errorf("发现 %d 个 %s 及以上级别的问题", n, failOn)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("path", "p", ".", "审计路径")
+	cmd.Flags().StringArray("exclude", nil, "排除匹配的文件或目录（通配符，支持 **，可多次指定）")
+	cmd.Flags().StringSlice("allow-owner", nil, "允许的属主（用户名或 UID，可多个），未指定时不检查属主")
+	cmd.Flags().String("fail-on", "high", "发现该级别及以上的问题时以非零状态退出（low|medium|high|critical|none）；路径无法读取时总是失败")
+	cmd.Flags().StringP("output", "o", "text", "输出格式（text|json）")
+	return cmd
+}
+
+// lookupOwners 把用户名或数字 UID 转换为 UID 集合
+func lookupOwners(names []string) (map[uint32]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	owners := make(map[uint32]bool)
+	for _, name := range names {
+		if uid, err := strconv.ParseUint(name, 10, 32); err == nil {
+			owners[uint32(uid)] = true
+			continue
+		}
+		u, err := user.Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 --allow-owner %q: %w", name, err)
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("用户 %s 没有数字 UID（当前平台不支持属主检查）", name)
+		}
+		owners[uint32(uid)] = true
+	}
+	return owners, nil
+}
+
+// audit 遍历 opts.Root 并检查每个条目，不跟随符号链接。
+// 无法访问的条目记录到错误列表后继续；ctx 取消时返回已检查部分的结果。
+func audit(ctx context.Context, opts auditOptions) ([]finding, []jsonError) {
+	var findings []finding
+	var errs []jsonError
+
+	filepath.WalkDir(opts.Root, func(p string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			errs = append(errs, jsonError{Path: p, Error: err.Error()})
+			return nil
+		}
+		if p != opts.Root {
+			rel := relPath(opts.Root, p)
+			if d.IsDir() && opts.Filter.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && !opts.Filter.MatchFile(rel) {
+				return nil
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			errs = append(errs, jsonError{Path: p, Error: err.Error()})
+			return nil
+		}
+		findings = append(findings, checkEntry(p, info, opts)...)
+		return nil
+	})
+
+	// 最严重的排在前面，同级别按路径排序
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].Path < findings[j].Path
+	})
+	return findings, errs
+}
+
+// checkEntry 对单个条目执行全部检查
+func checkEntry(p string, info fs.FileInfo, opts auditOptions) []finding {
+	var out []finding
+	mode := info.Mode()
+	add := func(sev severity, check, detail string) {
+		out = append(out, finding{Severity: sev, Check: check, Path: p, Mode: mode.String(), Detail: detail})
+	}
+
+	if mode&os.ModeSymlink != 0 {
+		if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+			target, _ := os.Readlink(p)
+			add(severityLow, "dangling-link", "符号链接指向不存在的目标 "+target)
+		}
+		return out // 链接本身的权限位没有意义
+	}
+
+	perm := mode.Perm()
+	switch {
+	case mode.IsDir() && perm&0o002 != 0 && mode&os.ModeSticky != 0:
+		add(severityLow, "world-writable", "其他用户可写的目录（已设置粘滞位）")
+	case mode.IsDir() && perm&0o002 != 0:
+		add(severityHigh, "world-writable", "其他用户可写的目录，任何人都能替换其中的文件")
+	case perm&0o002 != 0:
+		add(severityHigh, "world-writable", "其他用户可写的文件")
+	}
+
+	uid, hasOwner := fileUID(info)
+	if mode.IsRegular() {
+		if mode&os.ModeSetuid != 0 {
+			if hasOwner && uid == 0 {
+				add(severityCritical, "setuid", "属主为 root 的 setuid 文件")
+			} else {
+				add(severityHigh, "setuid", "设置了 setuid 位")
+			}
+		}
+		if mode&os.ModeSetgid != 0 {
+			add(severityMedium, "setgid", "设置了 setgid 位")
+		}
+		if isSensitive(info.Name()) && perm&0o077 != 0 {
+			add(severityHigh, "permissive", "私钥或凭据文件可被同组或其他用户访问，建议权限 0600")
+		} else if perm&0o022 == 0o020 { // 其他用户可写已在上面报告
+			add(severityLow, "permissive", "同组用户可写")
+		}
+	}
+
+	if opts.Owners != nil && hasOwner && !opts.Owners[uid] {
+		add(severityMedium, "owner", fmt.Sprintf("属主 UID %d 不在允许列表中", uid))
+	}
+	return out
+}
+
+// writeFindings 输出审计结果：text 为表格加按级别汇总，json 为结构化数据
+func writeFindings(format string, w io.Writer, findings []finding, errs []jsonError) error {
+	counts := make(map[string]int)
+	for _, name := range severityNames {
+		counts[name] = 0 // 没有发现的级别也输出 0
+	}
+	for _, f := range findings {
+		counts[f.Severity.String()]++
+	}
+
+	if format == "json" {
+		doc := struct {
+			SchemaVersion int            `json:"schema_version"`
+			Findings      []finding      `json:"findings"`
+			Errors        []jsonError    `json:"errors"`
+			Summary       map[string]int `json:"summary"`
+		}{schemaVersion, findings, errs, counts}
+		if doc.Findings == nil {
+			doc.Findings = []finding{}
+		}
+		if doc.Errors == nil {
+			doc.Errors = []jsonError{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	printErrors(errs)
+	if len(findings) == 0 {
+		msg := "未发现问题"
+		if len(errs) > 0 {
+			msg = "未发现问题（有路径无法读取，结果不完整）"
+		}
+		_, err := fmt.Fprintln(w, msg)
+		return err
+	}
+	t := table.New("级别", "检查项", "权限", "路径", "说明")
+	for _, f := range findings {
+		t.Row(f.Severity.String(), f.Check, f.Mode, f.Path, f.Detail)
+	}
+	t.Render(w)
+	fmt.Fprintln(w, "----")
+	_, err := fmt.Fprintf(w, "critical: %d  high: %d  medium: %d  low: %d\n",
+		counts["critical"], counts["high"], counts["medium"], counts["low"])
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// fileUID 非 Unix 平台没有 UID，跳过属主检查
+func fileUID(info fs.FileInfo) (uint32, bool) {
+	return 0, false
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// partialHashSize 预筛选阶段只读取文件开头的字节数
+const partialHashSize = 4096
+
+// dupeGroup 一组内容完全相同的文件
+type dupeGroup struct {
+	Hash  string   // 完整内容的 SHA-256
+	Size  int64    // 单个文件大小
+	Paths []string // 文件路径（已排序）
+}
+
+// Reclaimable 删除重复副本后可回收的字节数（保留一份）
+func (g dupeGroup) Reclaimable() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// hashFile 计算文件的 SHA-256；limit > 0 时只读取前 limit 字节
+func hashFile(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit > 0 {
+		r = io.LimitReader(f, limit)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashAll 用固定数量的 worker 并发计算哈希，返回 路径 -> 哈希。
+// 失败的文件通过 onError 报告，不出现在结果中；每完成一个文件调用一次 onDone（可为 nil）。
+// ctx 取消后不再分派新文件，返回已完成的部分。
+func hashAll(ctx context.Context, paths []string, limit int64, workers int, onError func(string, error), onDone func()) map[string]string {
+	type result struct {
+		path string
+		hash string
+		err  error
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				h, err := hashFile(p, limit)
+				results <- result{path: p, hash: h, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, p := range paths {
+			select {
+			case jobs <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	hashes := make(map[string]string, len(paths))
+	for r := range results {
+		if onDone != nil {
+			onDone()
+		}
+		if r.err != nil {
+			onError(r.path, r.err)
+			continue
+		}
+		hashes[r.path] = r.hash
+	}
+	return hashes
+}
+
+// groupByHash 将同一大小的候选文件按哈希分组，只保留至少两个文件的组
+func groupByHash(paths []string, hashes map[string]string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		if h, ok := hashes[p]; ok {
+			groups[h] = append(groups[h], p)
+		}
+	}
+	for h, g := range groups {
+		if len(g) < 2 {
+			delete(groups, h)
+		}
+	}
+	return groups
+}
+
+// fileInode 设备号和 inode 号，相同时是同一个文件的硬链接
+type fileInode struct {
+	Dev, Ino uint64
+}
+
+// uniqueInodes 同一个文件的多个硬链接只保留路径最小的一个：它们不占用额外空间，不是重复文件
+func uniqueInodes(files []fileEntry) []fileEntry {
+	seen := make(map[fileInode]int) // inode -> 在结果中的下标
+	out := make([]fileEntry, 0, len(files))
+	for _, f := range files {
+		if info, err := os.Lstat(f.Path); err == nil {
+			if id, ok := inodeOf(info); ok {
+				if i, dup := seen[id]; dup {
+					if f.Path < out[i].Path {
+						out[i] = f
+					}
+					continue
+				}
+				seen[id] = len(out)
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// findDupes 查找重复文件：先去掉硬链接，按大小分组，再用文件头部哈希预筛，最后用完整 SHA-256 确认
+func findDupes(ctx context.Context, files []fileEntry, workers int, onError func(string, error)) []dupeGroup {
+	files = uniqueInodes(files)
+
+	// 1. 按大小分组，大小唯一的文件不可能重复
+	bySize := make(map[int64][]string)
+	for _, f := range files {
+		bySize[f.Size] = append(bySize[f.Size], f.Path)
+	}
+
+	var candidates []string
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			delete(bySize, size)
+			continue
+		}
+		candidates = append(candidates, paths...)
+	}
+
+	// 2. 部分哈希预筛
+	partial := hashAll(ctx, candidates, partialHashSize, workers, onError, nil)
+
+	var groups []dupeGroup
+	type sizeGroup struct {
+		size  int64
+		paths []string
+	}
+	var needFull []sizeGroup // 需要完整哈希确认的候选组
+	for size, paths := range bySize {
+		for h, g := range groupByHash(paths, partial) {
+			// 文件不超过预筛长度时，部分哈希就是完整哈希
+			if size <= partialHashSize {
+				groups = append(groups, dupeGroup{Hash: h, Size: size, Paths: g})
+				continue
+			}
+			needFull = append(needFull, sizeGroup{size, g})
+		}
+	}
+
+	// 3. 完整 SHA-256 确认
+	var fullPaths []string
+	for _, g := range needFull {
+		fullPaths = append(fullPaths, g.paths...)
+	}
+	full := hashAll(ctx, fullPaths, 0, workers, onError, nil)
+
+	for _, g := range needFull {
+		for h, same := range groupByHash(g.paths, full) {
+			groups = append(groups, dupeGroup{Hash: h, Size: g.size, Paths: same})
+		}
+	}
+
+	// 输出顺序稳定：可回收空间大的在前
+	for i := range groups {
+		sort.Strings(groups[i].Paths)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Reclaimable() != groups[j].Reclaimable() {
+			return groups[i].Reclaimable() > groups[j].Reclaimable()
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups
+}
+
+// writeDupes 按输出格式输出重复文件分组
+func writeDupes(format string, w io.Writer, opts scanOptions, groups []dupeGroup, errs []jsonError, summary scanSummary) error {
+	var reclaimable int64
+	for _, g := range groups {
+		reclaimable += g.Reclaimable()
+	}
+
+	switch format {
+	case "json":
+		type jsonGroup struct {
+			Hash        string   `json:"hash"`
+			Size        int64    `json:"size"`
+			Reclaimable int64    `json:"reclaimable"`
+			Paths       []string `json:"paths"`
+		}
+		doc := struct {
+			SchemaVersion int         `json:"schema_version"`
+			Params        jsonParams  `json:"params"`
+			Groups        []jsonGroup `json:"groups"`
+			Reclaimable   int64       `json:"reclaimable"`
+			Errors        []jsonError `json:"errors"`
+			Summary       jsonSummary `json:"summary"`
+		}{
+			SchemaVersion: schemaVersion,
+			Params:        toJSONParams(opts),
+			Groups:        []jsonGroup{},
+			Reclaimable:   reclaimable,
+			Errors:        errs,
+			Summary:       toJSONSummary(summary),
+		}
+		for _, g := range groups {
+			doc.Groups = append(doc.Groups, jsonGroup{g.Hash, g.Size, g.Reclaimable(), g.Paths})
+		}
+		if doc.Errors == nil {
+			doc.Errors = []jsonError{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, g := range groups {
+			enc.Encode(map[string]any{
+				"type": "dupe", "hash": g.Hash, "size": g.Size,
+				"reclaimable": g.Reclaimable(), "paths": g.Paths,
+			})
+		}
+		for _, e := range errs {
+			enc.Encode(map[string]any{"type": "error", "path": e.Path, "error": e.Error})
+		}
+		return enc.Encode(map[string]any{
+			"type": "summary", "schema_version": schemaVersion,
+			"groups": len(groups), "reclaimable": reclaimable,
+			"canceled": summary.Canceled,
+		})
+
+	case "csv":
+		printErrors(errs)
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"group", "hash", "size", "path"})
+		for i, g := range groups {
+			for _, p := range g.Paths {
+				cw.Write([]string{strconv.Itoa(i + 1), g.Hash, strconv.FormatInt(g.Size, 10), p})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		printErrors(errs)
+		for i, g := range groups {
+			fmt.Fprintf(w, "重复组 %d：%d 个文件，每个 %d字节，可回收 %d字节\n", i+1, len(g.Paths), g.Size, g.Reclaimable())
+			for _, p := range g.Paths {
+				fmt.Fprintf(w, "  %s\n", p)
+			}
+		}
+		fmt.Fprintln(w, "----")
+		fmt.Fprintf(w, "扫描文件: %d\n", summary.Scanned)
+		fmt.Fprintf(w, "候选文件: %d\n", summary.Matched)
+		fmt.Fprintf(w, "重复组数: %d\n", len(groups))
+		fmt.Fprintf(w, "可回收: %d字节\n", reclaimable)
+		return printCanceled(w, summary)
+	}
+}
+
+// printErrors 将收集到的错误输出到标准错误
+func printErrors(errs []jsonError) {
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "跳过 %s: %s\n", e.Path, e.Error)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// inodeOf 非 Unix 平台无法取得 inode，不识别硬链接
+func inodeOf(info fs.FileInfo) (fileInode, bool) {
+	return fileInode{}, false
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package main
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// inodeOf 读取文件所在设备和 inode 号，用于识别同一文件的硬链接
+func inodeOf(info fs.FileInfo) (fileInode, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileInode{}, false
+	}
+	return fileInode{Dev: uint64(st.Dev), Ino: uint64(st.Ino)}, true
+}
@@ -1,34 +1,251 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
 
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
 	"github.com/spf13/cobra"
 )
 
 func main() {
+	// 自定义类型的参数（实现 pflag.Value 接口，解析时校验格式）
+	minSize := newSizeValue(1024)
+	maxSize := newSizeValue(0)
+	var newerThan, olderThan ageValue
+
 	rootCmd := &cobra.Command{
 		Use:   "filecheck",
 		Short: "文件检查工具",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// 获取所有参数
 			path, _ := cmd.Flags().GetString("path")
 			recursive, _ := cmd.Flags().GetBool("recursive") // 是否启用递归模式
-			minSize, _ := cmd.Flags().GetInt64("min-size")
 			exts, _ := cmd.Flags().GetStringSlice("ext")
+			output, _ := cmd.Flags().GetString("output")
+			dupes, _ := cmd.Flags().GetBool("dupes")
+			workers, _ := cmd.Flags().GetInt("workers")
+			include, _ := cmd.Flags().GetStringArray("include")
+			exclude, _ := cmd.Flags().GetStringArray("exclude")
+			ignoreFile, _ := cmd.Flags().GetString("ignore-file")
+			watchMode, _ := cmd.Flags().GetBool("watch")
+			debounce, _ := cmd.Flags().GetDuration("debounce")
+			mimes, _ := cmd.Flags().GetStringSlice("mime")
+			mismatchOnly, _ := cmd.Flags().GetBool("mime-mismatch")
+			report, _ := cmd.Flags().GetBool("report")
+			top, _ := cmd.Flags().GetInt("top")
+			actionFlag, _ := cmd.Flags().GetString("action")
+
+			filter, err := pathmatch.NewFilter(include, exclude, ignoreFile)
+			if err != nil {
+				return err
+			}
+			mimes, err = normalizeMIMEs(mimes)
+			if err != nil {
+				return err
+			}
+
+			opts := scanOptions{
+				Root:      path,
+				Recursive: recursive,
+				MinSize:   int64(*minSize),
+				MaxSize:   int64(*maxSize),
+				NewerThan: newerThan.Time(),
+				OlderThan: olderThan.Time(),
+				Exts:      normalizeExts(exts),
+
+				MIMEs:        mimes,
+				MismatchOnly: mismatchOnly,
+
+				Include:    include,
+				Exclude:    exclude,
+				IgnoreFile: ignoreFile,
+				Filter:     filter,
+
+				Workers: workers,
+			}
+
+			if dupes {
+				return runDupes(cmd, opts, output, workers)
+			}
+			if report {
+				return runReport(cmd, opts, output, top)
+			}
+			if actionFlag != "" {
+				a, _ := parseAction(actionFlag) // 已在 PreRunE 中校验
+				yes, _ := cmd.Flags().GetBool("yes")
+				trashDir, _ := cmd.Flags().GetString("trash-dir")
+				logPath, _ := cmd.Flags().GetString("audit-log")
+				return runAction(cmd, opts, actionOptions{Action: a, Yes: yes, TrashDir: trashDir, LogPath: logPath})
+			}
+
+			rep := newReporter(output, cmd.OutOrStdout(), opts)
+			summary := scan(cmd.Context(), opts, rep.Match, rep.Error)
+			if err := rep.Finish(summary); err != nil {
+				return err
+			}
+			if summary.Canceled {
+				return interrupted(cmd)
+			}
+			if !watchMode {
+				return nil
+			}
 
-			fmt.Printf("检查路径: %s\n", path)
-			fmt.Printf("递归模式: %v\n", recursive)
-			fmt.Printf("最小尺寸: %d字节\n", minSize)
-			fmt.Printf("扩展名过滤: %v\n", exts)
+			// 初始扫描完成后持续监控，直到收到 Ctrl-C 或 SIGTERM
+			fmt.Fprintf(os.Stderr, "开始监控 %s（Ctrl-C 退出）\n", opts.Root)
+			return watch(cmd.Context(), opts, debounce, newEventWriter(output, cmd.OutOrStdout()))
 		},
 	}
 
 	// 支持不同数据类型
 	rootCmd.Flags().StringP("path", "p", ".", "检查路径")
 	rootCmd.Flags().BoolP("recursive", "r", false, "递归检查")
-	rootCmd.Flags().Int64P("min-size", "s", 1024, "最小文件大小（字节）")
+	rootCmd.Flags().VarP(minSize, "min-size", "s", "最小文件大小，支持单位：512k、10MB、1.5GiB")
+	rootCmd.Flags().Var(maxSize, "max-size", "最大文件大小（0 表示不限制），单位同 --min-size")
+	rootCmd.Flags().Var(&newerThan, "newer-than", "只检查在此之后修改的文件：时长（72h、30d、2w）或日期（2024-01-31）")
+	rootCmd.Flags().Var(&olderThan, "older-than", "只检查在此之前修改的文件，格式同 --newer-than")
 	rootCmd.Flags().StringSliceP("ext", "e", []string{}, "按扩展名过滤（可多个）")
+	rootCmd.Flags().StringSlice("mime", []string{}, "按文件头检测的内容类型过滤（可多个），如 image/*、application/pdf")
+	rootCmd.Flags().Bool("mime-mismatch", false, "只检查扩展名与内容类型不符的文件（如伪装成图片的可执行文件）")
+	rootCmd.Flags().StringArray("include", nil, "只检查匹配的文件（通配符，支持 **，可多次指定）")
+	rootCmd.Flags().StringArray("exclude", nil, "排除匹配的文件或目录（通配符，支持 **，可多次指定）")
+	rootCmd.Flags().String("ignore-file", "", "gitignore 格式的忽略文件")
+	rootCmd.Flags().StringP("output", "o", "text", "输出格式（text|json|ndjson|csv）")
+	rootCmd.Flags().Bool("dupes", false, "查找内容重复的文件")
+	rootCmd.Flags().Bool("report", false, "输出汇总报告：扩展名统计、大小分布、最大和最旧的文件")
+	rootCmd.Flags().Int("top", 10, "报告中列出最大和最旧文件的数量")
+	rootCmd.Flags().Bool("watch", false, "初始扫描后持续监控文件变化（仅 Linux）")
+	rootCmd.Flags().Duration("debounce", 500*time.Millisecond, "监控模式下合并连续事件的时间窗口")
+	rootCmd.Flags().String("action", "", "对匹配的文件执行操作：delete、move:<dir>、gzip、trash（默认只预演）")
+	rootCmd.Flags().BoolP("yes", "y", false, "确认执行 --action（不指定时只输出将要执行的操作）")
+	rootCmd.Flags().String("trash-dir", filepath.Join(dataDir(), "trash"), "回收站目录，每次运行创建一个带恢复清单的子目录")
+	rootCmd.Flags().String("audit-log", filepath.Join(dataDir(), "actions.log"), "记录每个操作的日志文件")
+	rootCmd.Flags().IntP("workers", "w", runtime.NumCPU(), "并发 worker 数量（用于并行读取目录和计算哈希）")
+
+	// 参数验证：返回错误而不是直接退出，由 Cobra 统一输出错误信息
+	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if err := validOutput(output); err != nil {
+			return err
+		}
+
+		if *maxSize > 0 && *maxSize < *minSize {
+			return fmt.Errorf("--max-size（%s）不能小于 --min-size（%s）", maxSize, minSize)
+		}
+		if !newerThan.Time().IsZero() && !olderThan.Time().IsZero() && !newerThan.Time().Before(olderThan.Time()) {
+			return fmt.Errorf("--newer-than（%s）必须早于 --older-than（%s）", newerThan.String(), olderThan.String())
+		}
+
+		if report, _ := cmd.Flags().GetBool("report"); report {
+			if dupes, _ := cmd.Flags().GetBool("dupes"); dupes {
+				return fmt.Errorf("--report 不能与 --dupes 同时使用")
+			}
+			if watchMode, _ := cmd.Flags().GetBool("watch"); watchMode {
+				return fmt.Errorf("--report 不能与 --watch 同时使用")
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("--report 只支持 text 或 json 输出，当前为 %q", output)
+			}
+		}
+		if actionFlag, _ := cmd.Flags().GetString("action"); actionFlag != "" {
+			if _, err := parseAction(actionFlag); err != nil {
+				return err
+			}
+			for _, other := range []string{"dupes", "report", "watch"} {
+				if on, _ := cmd.Flags().GetBool(other); on {
+					return fmt.Errorf("--action 不能与 --%s 同时使用", other)
+				}
+			}
+			if output != "text" {
+				return fmt.Errorf("--action 只支持 text 输出，当前为 %q", output)
+			}
+		}
+		if top, _ := cmd.Flags().GetInt("top"); top < 0 {
+			return fmt.Errorf("--top 不能为负数")
+		}
+
+		watchMode, _ := cmd.Flags().GetBool("watch")
+		if !watchMode {
+			return nil
+		}
+		if err := checkWatchSupported(); err != nil {
+			return err
+		}
+		if dupes, _ := cmd.Flags().GetBool("dupes"); dupes {
+			return fmt.Errorf("--watch 不能与 --dupes 同时使用")
+		}
+		return validWatchOutput(output)
+	}
+
+	rootCmd.AddCommand(newAuditCmd(), newManifestCmd(), newRestoreCmd())
+
+	// Ctrl-C 或 SIGTERM 时取消 context，通过 ExecuteContext 传递给所有子命令（cmd.Context()）。
+	// 取消后恢复默认信号处理，再按一次 Ctrl-C 可以强制退出。
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// errInterrupted 扫描被 Ctrl-C 或 SIGTERM 中断
+var errInterrupted = errors.New("已中断，结果不完整")
+
+// interrupted 中断不是用法错误，不输出帮助信息
+func interrupted(cmd *cobra.Command) error {
+	cmd.SilenceUsage = true
+	return errInterrupted
+}
+
+// runDupes 先按过滤条件收集候选文件，再查找重复文件并输出
+func runDupes(cmd *cobra.Command, opts scanOptions, output string, workers int) error {
+	var files []fileEntry
+	var errs []jsonError
+	onError := func(p string, err error) {
+		errs = append(errs, jsonError{Path: p, Error: err.Error()})
+	}
+
+	summary := scan(cmd.Context(), opts, func(f fileEntry) { files = append(files, f) }, onError)
+	groups := findDupes(cmd.Context(), files, workers, onError)
+	summary.Errors = len(errs)
+	summary.Canceled = cmd.Context().Err() != nil
 
-	rootCmd.Execute()
+	if err := writeDupes(output, cmd.OutOrStdout(), opts, groups, errs, summary); err != nil {
+		return err
+	}
+	if summary.Canceled {
+		return interrupted(cmd)
+	}
+	return nil
+}
+
+// runReport 扫描匹配的文件并输出汇总报告
+func runReport(cmd *cobra.Command, opts scanOptions, output string, top int) error {
+	b := newReportBuilder(top)
+	var errs []jsonError
+	onError := func(p string, err error) {
+		errs = append(errs, jsonError{Path: p, Error: err.Error()})
+	}
+
+	summary := scan(cmd.Context(), opts, b.Add, onError)
+	if err := writeReport(output, cmd.OutOrStdout(), opts, b.Result(), errs, summary); err != nil {
+		return err
+	}
+	if summary.Canceled {
+		return interrupted(cmd)
+	}
+	return nil
 }
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
+	"github.com/spf13/cobra"
+)
+
+// manifestEntry 清单中的一个文件，路径相对于清单根目录、以 / 分隔
+type manifestEntry struct {
+	Path    string    `json:"path"`
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// manifestDoc JSON 格式的清单，比 sha256sum 格式多记录大小和修改时间
+type manifestDoc struct {
+	SchemaVersion int             `json:"schema_version"`
+	Algorithm     string          `json:"algorithm"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Files         []manifestEntry `json:"files"`
+}
+
+// newManifestCmd 生成和校验校验和清单，用于确认目录在主机间复制后内容一致
+func newManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "生成和校验 SHA-256 清单",
+	}
+	cmd.PersistentFlags().StringP("path", "p", ".", "清单根目录")
+	cmd.PersistentFlags().StringArray("exclude", nil, "排除匹配的文件或目录（通配符，支持 **，可多次指定）")
+	cmd.PersistentFlags().IntP("workers", "w", runtime.NumCPU(), "并发计算哈希的文件数")
+
+	cmd.AddCommand(newManifestCreateCmd(), newManifestVerifyCmd())
+	return cmd
+}
+
+func newManifestCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "生成清单（默认与 sha256sum 兼容）",
+		Example: `  filecheck manifest create -p dist -f dist.sha256
+  cd dist && sha256sum -c ../dist.sha256
+  filecheck manifest create -p dist --format json -f dist.json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, _ := cmd.Flags().GetString("path")
+			exclude, _ := cmd.Flags().GetStringArray("exclude")
+			workers, _ := cmd.Flags().GetInt("workers")
+			file, _ := cmd.Flags().GetString("file")
+			format, _ := cmd.Flags().GetString("format")
+
+			if format != "sha256sum" && format != "json" {
+				return fmt.Errorf("--format 必须是 sha256sum 或 json，当前为 %q", format)
+			}
+			filter, err := pathmatch.NewFilter(nil, exclude, "")
+			if err != nil {
+				return err
+			}
+
+			entries, err := buildManifest(cmd.Context(), root, filter, file, workers)
+			if err != nil {
+				return err
+			}
+			if cmd.Context().Err() != nil {
+				return fmt.Errorf("%w，未生成清单", errInterrupted)
+			}
+
+			var buf bytes.Buffer
+			if format == "json" {
+				err = writeManifestJSON(&buf, entries)
+			} else {
+				err = writeSHA256Sum(&buf, entries)
+			}
+			if err != nil {
+				return err
+			}
+			if file == "" {
+				_, err = cmd.OutOrStdout().Write(buf.Bytes())
+				return err
+			}
+			if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "已写入 %s（%d 个文件）\n", file, len(entries))
+			return nil
+		},
+	}
+	cmd.Flags().StringP("file", "f", "", "清单输出文件（默认输出到标准输出）")
+	cmd.Flags().String("format", "sha256sum", "清单格式（sha256sum|json）")
+	return cmd
+}
+
+func newManifestVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "verify MANIFEST",
+		Short:        "按清单校验目录，报告缺失、新增和被修改的文件",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, _ := cmd.Flags().GetString("path")
+			exclude, _ := cmd.Flags().GetStringArray("exclude")
+			workers, _ := cmd.Flags().GetInt("workers")
+			output, _ := cmd.Flags().GetString("output")
+
+			if output != "text" && output != "json" {
+				return fmt.Errorf("verify 只支持 text 或 json 输出，当前为 %q", output)
+			}
+			filter, err := pathmatch.NewFilter(nil, exclude, "")
+			if err != nil {
+				return err
+			}
+			expected, err := readManifest(args[0])
+			if err != nil {
+				return err
+			}
+
+			res := verifyManifest(cmd.Context(), root, filter, args[0], expected, workers)
+			if cmd.Context().Err() != nil {
+				return fmt.Errorf("%w，校验未完成", errInterrupted)
+			}
+			if err := writeVerifyResult(output, cmd.OutOrStdout(), res); err != nil {
+				return err
+			}
+			if n := len(res.Missing) + len(res.Added) + len(res.Modified) + len(res.Errors); n > 0 {
+				return fmt.Errorf("校验失败：%d 处不一致", n)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "text", "输出格式（text|json）")
+	return cmd
+}
+
+// listFiles 列出 root 下的所有普通文件（相对路径 -> 文件信息），skip 指定的文件不计入（如清单本身）
+func listFiles(ctx context.Context, root string, filter *pathmatch.Filter, skip string, workers int) (map[string]fileEntry, []jsonError) {
+	skipAbs := ""
+	if skip != "" {
+		skipAbs, _ = filepath.Abs(skip)
+	}
+
+	files := make(map[string]fileEntry)
+	var errs []jsonError
+	opts := scanOptions{Root: root, Recursive: true, Filter: filter, Workers: workers}
+	scan(ctx, opts, func(f fileEntry) {
+		if abs, _ := filepath.Abs(f.Path); abs == skipAbs {
+			return
+		}
+		files[relPath(root, f.Path)] = f
+	}, func(p string, err error) {
+		errs = append(errs, jsonError{Path: p, Error: err.Error()})
+	})
+	return files, errs
+}
+
+// hashFiles 并发计算文件哈希，标准错误是终端时显示进度
+func hashFiles(ctx context.Context, paths []string, workers int, onError func(string, error)) map[string]string {
+	p := newProgress("计算哈希", len(paths))
+	defer p.Finish()
+	return hashAll(ctx, paths, 0, workers, onError, p.Add)
+}
+
+// buildManifest 计算 root 下所有文件的哈希，按路径排序
+func buildManifest(ctx context.Context, root string, filter *pathmatch.Filter, skip string, workers int) ([]manifestEntry, error) {
+	files, errs := listFiles(ctx, root, filter, skip, workers)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w，未生成清单", errInterrupted)
+	}
+	if len(errs) > 0 {
+		printErrors(errs)
+		return nil, fmt.Errorf("%d 个条目无法读取，未生成清单", len(errs))
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	failed := 0
+	hashes := hashFiles(ctx, paths, workers, func(p string, err error) {
+		failed++
+		fmt.Fprintf(os.Stderr, "跳过 %s: %v\n", p, err)
+	})
+	if failed > 0 {
+		return nil, fmt.Errorf("%d 个文件无法读取，未生成清单", failed)
+	}
+
+	entries := make([]manifestEntry, 0, len(files))
+	for rel, f := range files {
+		entries = append(entries, manifestEntry{Path: rel, SHA256: hashes[f.Path], Size: f.Size, ModTime: f.ModTime})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// writeSHA256Sum 输出 sha256sum 格式：哈希、两个空格、路径。
+// 与 GNU sha256sum 一致，路径含反斜杠或换行时行首加 \ 并转义。
+func writeSHA256Sum(w io.Writer, entries []manifestEntry) error {
+	for _, e := range entries {
+		name, prefix := e.Path, ""
+		if strings.ContainsAny(name, "\\\n\r") {
+			name = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(name)
+			prefix = `\`
+		}
+		if _, err := fmt.Fprintf(w, "%s%s  %s\n", prefix, e.SHA256, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeManifestJSON(w io.Writer, entries []manifestEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(manifestDoc{
+		SchemaVersion: schemaVersion,
+		Algorithm:     "sha256",
+		CreatedAt:     time.Now(),
+		Files:         entries,
+	})
+}
+
+// sha256sumLine sha256sum 输出的一行；* 表示二进制模式，校验时与文本模式相同
+var sha256sumLine = regexp.MustCompile(`^(\\?)([0-9a-fA-F]{64}) [ *](.+)$`)
+
+// readManifest 读取清单，内容以 { 开头时按 JSON 解析，否则按 sha256sum 格式解析。
+// sha256sum 格式没有大小信息，Size 记为 -1。
+func readManifest(path string) ([]manifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var doc manifestDoc
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("解析清单 %s 失败: %w", path, err)
+		}
+		if doc.Algorithm != "" && doc.Algorithm != "sha256" {
+			return nil, fmt.Errorf("不支持的哈希算法 %q", doc.Algorithm)
+		}
+		return doc.Files, nil
+	}
+
+	var entries []manifestEntry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := sha256sumLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("%s:%d: 无法解析的清单行", path, n)
+		}
+		name := m[3]
+		if m[1] != "" {
+			name = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r").Replace(name)
+		}
+		entries = append(entries, manifestEntry{Path: filepath.ToSlash(name), SHA256: strings.ToLower(m[2]), Size: -1})
+	}
+	return entries, sc.Err()
+}
+
+// verifyResult 校验结果，各列表均按路径排序
+type verifyResult struct {
+	Checked  int         `json:"checked"`
+	OK       int         `json:"ok"`
+	Missing  []string    `json:"missing"`
+	Added    []string    `json:"added"`
+	Modified []string    `json:"modified"`
+	Errors   []jsonError `json:"errors"`
+}
+
+// verifyManifest 对比清单与目录现状。JSON 清单记录了大小，大小不同的文件直接判为已修改，不再计算哈希；
+// 修改时间在复制后通常会变化，不参与比较。
+func verifyManifest(ctx context.Context, root string, filter *pathmatch.Filter, manifestPath string, expected []manifestEntry, workers int) verifyResult {
+	res := verifyResult{Missing: []string{}, Added: []string{}, Modified: []string{}}
+	listed, errs := listFiles(ctx, root, filter, manifestPath, workers)
+	res.Errors = append([]jsonError{}, errs...)
+
+	// 两边的路径都规范化后再比较，清单中手写的 ./a.txt、a//b 也能与遍历结果对应
+	actual := make(map[string]fileEntry, len(listed))
+	for rel, f := range listed {
+		actual[path.Clean(rel)] = f
+	}
+	inManifest := make(map[string]bool, len(expected))
+	var toHash []string
+	for _, e := range expected {
+		inManifest[path.Clean(e.Path)] = true
+		f, ok := actual[path.Clean(e.Path)]
+		switch {
+		case !ok:
+			res.Missing = append(res.Missing, e.Path)
+		case e.Size >= 0 && e.Size != f.Size:
+			res.Modified = append(res.Modified, e.Path)
+		default:
+			toHash = append(toHash, f.Path)
+		}
+	}
+	for rel := range actual {
+		if !inManifest[rel] {
+			res.Added = append(res.Added, rel)
+		}
+	}
+
+	hashes := hashFiles(ctx, toHash, workers, func(p string, err error) {
+		res.Errors = append(res.Errors, jsonError{Path: p, Error: err.Error()})
+	})
+	for _, e := range expected {
+		f, ok := actual[path.Clean(e.Path)]
+		if !ok {
+			continue
+		}
+		h, ok := hashes[f.Path]
+		if !ok {
+			continue // 大小不同或读取失败，已在上面记录
+		}
+		res.Checked++
+		if h == e.SHA256 {
+			res.OK++
+		} else {
+			res.Modified = append(res.Modified, e.Path)
+		}
+	}
+
+	sort.Strings(res.Missing)
+	sort.Strings(res.Added)
+	sort.Strings(res.Modified)
+	return res
+}
+
+func writeVerifyResult(format string, w io.Writer, res verifyResult) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			SchemaVersion int `json:"schema_version"`
+			verifyResult
+		}{schemaVersion, res})
+	}
+
+	printErrors(res.Errors)
+	for _, p := range res.Modified {
+		fmt.Fprintf(w, "修改\t%s\n", p)
+	}
+	for _, p := range res.Missing {
+		fmt.Fprintf(w, "缺失\t%s\n", p)
+	}
+	for _, p := range res.Added {
+		fmt.Fprintf(w, "新增\t%s\n", p)
+	}
+	fmt.Fprintln(w, "----")
+	fmt.Fprintf(w, "一致: %d/%d\n", res.OK, res.Checked)
+	fmt.Fprintf(w, "修改: %d\n", len(res.Modified))
+	fmt.Fprintf(w, "缺失: %d\n", len(res.Missing))
+	fmt.Fprintf(w, "新增: %d\n", len(res.Added))
+	_, err := fmt.Fprintf(w, "错误数: %d\n", len(res.Errors))
+	return err
+}
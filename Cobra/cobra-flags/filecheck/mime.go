@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// normalizeMIMEs 统一 --mime 参数为小写并校验格式：type/subtype、type/* 或 *
+func normalizeMIMEs(patterns []string) ([]string, error) {
+	result := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if p != "*" && (strings.Count(p, "/") != 1 || strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/")) {
+			return nil, fmt.Errorf("无效的 --mime %q，格式应为 type/subtype 或 type/*", p)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// lineage 返回检测结果及其祖先类型（如 text/html -> text/plain）。
+// 所有类型的根都是 application/octet-stream，除非检测结果本身就是它，否则不计入，
+// 避免 application/* 匹配到任意文件。
+func lineage(mt *mimetype.MIME) []*mimetype.MIME {
+	var ms []*mimetype.MIME
+	for m := mt; m != nil; m = m.Parent() {
+		if m != mt && m.Parent() == nil {
+			break
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// matchMIME 判断检测到的类型是否匹配任一模式（列表为空时全部匹配），祖先类型也参与匹配，
+// 例如 application/zip 能匹配 docx，text/plain 能匹配所有文本文件
+func matchMIME(mt *mimetype.MIME, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, m := range lineage(mt) {
+		for _, p := range patterns {
+			if p == "*" || m.Is(p) {
+				return true
+			}
+			if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(m.String(), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTextMIME 检测结果是否为文本（文本类型都以 text/plain 为祖先）
+func isTextMIME(mt *mimetype.MIME) bool {
+	for m := mt; m != nil; m = m.Parent() {
+		if m.Is("text/plain") {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextual 扩展名声明的类型是否属于文本格式（源码、配置、标记语言等）
+func isTextual(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, s := range []string{"json", "xml", "javascript", "ecmascript", "yaml", "toml", "x-sh"} {
+		if strings.Contains(mediaType, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// extMismatch 判断文件扩展名与检测到的内容类型是否不符。
+// 没有扩展名或无法从扩展名推断类型时不判断；文本内容配文本类扩展名（.js、.css 等）视为相符，
+// 因为魔数检测只能识别出 text/plain。
+func extMismatch(path string, mt *mimetype.MIME) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" || strings.EqualFold(mt.Extension(), ext) {
+		return false
+	}
+	claimed, _, err := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if err != nil {
+		return false
+	}
+	for m := mt; m != nil; m = m.Parent() {
+		if m.Is(claimed) {
+			return false
+		}
+	}
+	return !(isTextMIME(mt) && isTextual(claimed))
+}
+
+// matchContent 读取文件头检测内容类型并按 --mime 和 --mime-mismatch 过滤。
+// 返回的类型名称不含参数（如 charset），mismatch 表示扩展名与内容不符。
+func (o scanOptions) matchContent(path string) (mimeType string, mismatch, ok bool, err error) {
+	mt, err := mimetype.DetectFile(path)
+	if err != nil {
+		return "", false, false, err
+	}
+	mimeType, _, _ = strings.Cut(mt.String(), ";")
+	mismatch = extMismatch(path, mt)
+	ok = matchMIME(mt, o.MIMEs) && (!o.MismatchOnly || mismatch)
+	return mimeType, mismatch, ok, nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// schemaVersion JSON 输出的结构版本，字段有不兼容变化时递增
+const schemaVersion = 1
+
+// outputFormats 支持的输出格式
+var outputFormats = []string{"text", "json", "ndjson", "csv"}
+
+// validOutput 校验输出格式是否受支持
+func validOutput(format string) error {
+	for _, f := range outputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("输出格式必须是 text、json、ndjson 或 csv，当前为 %q", format)
+}
+
+// reporter 扫描结果的输出方式
+type reporter interface {
+	Match(f fileEntry)
+	Error(path string, err error)
+	Finish(summary scanSummary) error
+}
+
+// newReporter 按格式创建输出器
+func newReporter(format string, w io.Writer, opts scanOptions) reporter {
+	switch format {
+	case "json":
+		return &jsonReporter{w: w, opts: opts}
+	case "ndjson":
+		return &ndjsonReporter{enc: json.NewEncoder(w)}
+	case "csv":
+		return newCSVReporter(w, opts.detectContent())
+	default:
+		return &textReporter{w: w, detect: opts.detectContent()}
+	}
+}
+
+// 以下为 JSON 输出使用的结构（字段名即对外约定，修改需递增 schemaVersion）
+
+type jsonParams struct {
+	Path      string     `json:"path"`
+	Recursive bool       `json:"recursive"`
+	MinSize   int64      `json:"min_size"`
+	MaxSize   int64      `json:"max_size"`
+	NewerThan *time.Time `json:"newer_than"`
+	OlderThan *time.Time `json:"older_than"`
+	Exts      []string   `json:"ext"`
+
+	Include    []string `json:"include"`
+	Exclude    []string `json:"exclude"`
+	IgnoreFile string   `json:"ignore_file"`
+
+	MIMEs        []string `json:"mime"`
+	MismatchOnly bool     `json:"mime_mismatch"`
+}
+
+type jsonFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	MIME     string `json:"mime,omitempty"`     // 只在检测内容类型时输出
+	Mismatch bool   `json:"mismatch,omitempty"` // 扩展名与内容不符
+}
+
+type jsonError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+type jsonSummary struct {
+	Scanned    int   `json:"scanned"`
+	Matched    int   `json:"matched"`
+	TotalBytes int64 `json:"total_bytes"`
+	Errors     int   `json:"errors"`
+	Mismatched int   `json:"mismatched"`
+	Canceled   bool  `json:"canceled"`
+}
+
+func toJSONParams(opts scanOptions) jsonParams {
+	return jsonParams{
+		Path:       opts.Root,
+		Recursive:  opts.Recursive,
+		MinSize:    opts.MinSize,
+		MaxSize:    opts.MaxSize,
+		NewerThan:  timeOrNil(opts.NewerThan),
+		OlderThan:  timeOrNil(opts.OlderThan),
+		Exts:       opts.Exts,
+		Include:    nonNil(opts.Include),
+		Exclude:    nonNil(opts.Exclude),
+		IgnoreFile: opts.IgnoreFile,
+
+		MIMEs:        nonNil(opts.MIMEs),
+		MismatchOnly: opts.MismatchOnly,
+	}
+}
+
+// timeOrNil 未设置的时间在 JSON 中输出为 null
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// nonNil 让空列表在 JSON 中输出为 [] 而不是 null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func toJSONFile(f fileEntry) jsonFile {
+	return jsonFile{Path: f.Path, Size: f.Size, ModTime: f.ModTime, MIME: f.MIME, Mismatch: f.Mismatch}
+}
+
+func toJSONSummary(s scanSummary) jsonSummary {
+	return jsonSummary{Scanned: s.Scanned, Matched: s.Matched, TotalBytes: s.TotalBytes, Errors: s.Errors, Mismatched: s.Mismatched, Canceled: s.Canceled}
+}
+
+// textReporter 面向人阅读的文本输出，错误写到标准错误
+type textReporter struct {
+	w      io.Writer
+	detect bool // 是否输出内容类型
+}
+
+func (r *textReporter) Match(f fileEntry) {
+	fmt.Fprintf(r.w, "%s\t%d字节\t%s", f.Path, f.Size, f.ModTime.Format("2006-01-02 15:04:05"))
+	if r.detect {
+		fmt.Fprintf(r.w, "\t%s", f.MIME)
+		if f.Mismatch {
+			fmt.Fprint(r.w, "\t扩展名与内容不符")
+		}
+	}
+	fmt.Fprintln(r.w)
+}
+
+func (r *textReporter) Error(path string, err error) {
+	fmt.Fprintf(os.Stderr, "跳过 %s: %v\n", path, err)
+}
+
+func (r *textReporter) Finish(s scanSummary) error {
+	fmt.Fprintln(r.w, "----")
+	fmt.Fprintf(r.w, "扫描文件: %d\n", s.Scanned)
+	fmt.Fprintf(r.w, "匹配文件: %d\n", s.Matched)
+	fmt.Fprintf(r.w, "总大小: %d字节\n", s.TotalBytes)
+	if r.detect {
+		fmt.Fprintf(r.w, "扩展名不符: %d\n", s.Mismatched)
+	}
+	fmt.Fprintf(r.w, "错误数: %d\n", s.Errors)
+	return printCanceled(r.w, s)
+}
+
+// printCanceled 扫描被中断时在文本汇总末尾注明
+func printCanceled(w io.Writer, s scanSummary) error {
+	if !s.Canceled {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, "状态: 已中断，以上为部分结果")
+	return err
+}
+
+// jsonReporter 扫描结束后输出一个完整的 JSON 文档
+type jsonReporter struct {
+	w      io.Writer
+	opts   scanOptions
+	files  []jsonFile
+	errors []jsonError
+}
+
+func (r *jsonReporter) Match(f fileEntry) {
+	r.files = append(r.files, toJSONFile(f))
+}
+
+func (r *jsonReporter) Error(path string, err error) {
+	r.errors = append(r.errors, jsonError{Path: path, Error: err.Error()})
+}
+
+func (r *jsonReporter) Finish(s scanSummary) error {
+	doc := struct {
+		SchemaVersion int         `json:"schema_version"`
+		Params        jsonParams  `json:"params"`
+		Files         []jsonFile  `json:"files"`
+		Errors        []jsonError `json:"errors"`
+		Summary       jsonSummary `json:"summary"`
+	}{
+		SchemaVersion: schemaVersion,
+		Params:        toJSONParams(r.opts),
+		Files:         r.files,
+		Errors:        r.errors,
+		Summary:       toJSONSummary(s),
+	}
+	// 保证空结果输出 [] 而不是 null，方便脚本处理
+	if doc.Files == nil {
+		doc.Files = []jsonFile{}
+	}
+	if doc.Errors == nil {
+		doc.Errors = []jsonError{}
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ndjsonReporter 每行一个 JSON 对象，边扫描边输出，用 type 字段区分记录类型
+type ndjsonReporter struct {
+	enc *json.Encoder
+}
+
+func (r *ndjsonReporter) Match(f fileEntry) {
+	r.enc.Encode(struct {
+		Type string `json:"type"`
+		jsonFile
+	}{"file", toJSONFile(f)})
+}
+
+func (r *ndjsonReporter) Error(path string, err error) {
+	r.enc.Encode(struct {
+		Type string `json:"type"`
+		jsonError
+	}{"error", jsonError{Path: path, Error: err.Error()}})
+}
+
+func (r *ndjsonReporter) Finish(s scanSummary) error {
+	return r.enc.Encode(struct {
+		Type          string `json:"type"`
+		SchemaVersion int    `json:"schema_version"`
+		jsonSummary
+	}{"summary", schemaVersion, toJSONSummary(s)})
+}
+
+// csvReporter 只输出匹配的文件，错误写到标准错误
+type csvReporter struct {
+	w      *csv.Writer
+	detect bool // 检测内容类型时追加 mime、mismatch 两列
+}
+
+func newCSVReporter(w io.Writer, detect bool) *csvReporter {
+	cw := csv.NewWriter(w)
+	header := []string{"path", "size", "mod_time"}
+	if detect {
+		header = append(header, "mime", "mismatch")
+	}
+	cw.Write(header)
+	return &csvReporter{w: cw, detect: detect}
+}
+
+func (r *csvReporter) Match(f fileEntry) {
+	record := []string{f.Path, strconv.FormatInt(f.Size, 10), f.ModTime.Format(time.RFC3339)}
+	if r.detect {
+		record = append(record, f.MIME, strconv.FormatBool(f.Mismatch))
+	}
+	r.w.Write(record)
+}
+
+func (r *csvReporter) Error(path string, err error) {
+	fmt.Fprintf(os.Stderr, "跳过 %s: %v\n", path, err)
+}
+
+func (r *csvReporter) Finish(s scanSummary) error {
+	r.w.Flush()
+	return r.w.Error()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progress 在终端上原地刷新的进度提示，只在标准错误是终端时显示。
+// 方法对 nil 接收者安全，调用方无需判断是否启用。
+type progress struct {
+	w     io.Writer
+	label string
+	total int
+	done  int
+	last  time.Time
+}
+
+// newProgress 标准错误不是终端（如重定向到文件或管道）时返回 nil
+func newProgress(label string, total int) *progress {
+	fi, err := os.Stderr.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &progress{w: os.Stderr, label: label, total: total}
+}
+
+// Add 完成一项；最多每 100ms 刷新一次，避免频繁写终端
+func (p *progress) Add() {
+	if p == nil {
+		return
+	}
+	p.done++
+	if p.done < p.total && time.Since(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = time.Now()
+	pct := 100
+	if p.total > 0 {
+		pct = p.done * 100 / p.total
+	}
+	fmt.Fprintf(p.w, "\r%s %d/%d (%d%%)", p.label, p.done, p.total, pct)
+}
+
+// Finish 清除进度行
+func (p *progress) Finish() {
+	if p == nil {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+}
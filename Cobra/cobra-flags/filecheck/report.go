@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+)
+
+// sizeBuckets 大小分布区间（上限不含），最后一个区间没有上限
+var sizeBuckets = []struct {
+	label string
+	limit int64
+}{
+	{"<1KB", 1 << 10},
+	{"<1MB", 1 << 20},
+	{"<100MB", 100 << 20},
+	{">=100MB", 0},
+}
+
+// noExt 没有扩展名的文件在统计中的名称
+const noExt = "(无扩展名)"
+
+// extStat 某个扩展名的统计
+type extStat struct {
+	Ext   string `json:"ext"`
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// bucketStat 某个大小区间的统计
+type bucketStat struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// reportBuilder 边扫描边汇总，只保留前 N 个最大和最旧的文件
+type reportBuilder struct {
+	top     int
+	byExt   map[string]*extStat
+	buckets []bucketStat
+	largest []fileEntry
+	oldest  []fileEntry
+}
+
+func newReportBuilder(top int) *reportBuilder {
+	b := &reportBuilder{top: top, byExt: make(map[string]*extStat)}
+	for _, sb := range sizeBuckets {
+		b.buckets = append(b.buckets, bucketStat{Label: sb.label})
+	}
+	return b
+}
+
+// Add 累计一个匹配的文件
+func (b *reportBuilder) Add(f fileEntry) {
+	ext := strings.ToLower(filepath.Ext(f.Path))
+	if ext == "" {
+		ext = noExt
+	}
+	s, ok := b.byExt[ext]
+	if !ok {
+		s = &extStat{Ext: ext}
+		b.byExt[ext] = s
+	}
+	s.Count++
+	s.Bytes += f.Size
+
+	for i, sb := range sizeBuckets {
+		if sb.limit == 0 || f.Size < sb.limit {
+			b.buckets[i].Count++
+			b.buckets[i].Bytes += f.Size
+			break
+		}
+	}
+
+	if b.top > 0 {
+		b.largest = keepTop(append(b.largest, f), b.top, func(x, y fileEntry) bool { return x.Size > y.Size })
+		b.oldest = keepTop(append(b.oldest, f), b.top, func(x, y fileEntry) bool { return x.ModTime.Before(y.ModTime) })
+	}
+}
+
+// keepTop 保留按 less 排序的前 n 个；积累到 2n 时才排序截断，避免每次都排序
+func keepTop(files []fileEntry, n int, less func(x, y fileEntry) bool) []fileEntry {
+	if len(files) < 2*n {
+		return files
+	}
+	return sortTop(files, n, less)
+}
+
+// sortTop 排序并截取前 n 个（相同时按路径排序，保证结果稳定）
+func sortTop(files []fileEntry, n int, less func(x, y fileEntry) bool) []fileEntry {
+	sort.Slice(files, func(i, j int) bool {
+		if less(files[i], files[j]) {
+			return true
+		}
+		if less(files[j], files[i]) {
+			return false
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// fileReport 汇总报告
+type fileReport struct {
+	Extensions []extStat
+	Buckets    []bucketStat
+	Largest    []fileEntry
+	Oldest     []fileEntry
+}
+
+// Result 生成最终报告：扩展名按总大小降序
+func (b *reportBuilder) Result() fileReport {
+	r := fileReport{Buckets: b.buckets}
+	for _, s := range b.byExt {
+		r.Extensions = append(r.Extensions, *s)
+	}
+	sort.Slice(r.Extensions, func(i, j int) bool {
+		if r.Extensions[i].Bytes != r.Extensions[j].Bytes {
+			return r.Extensions[i].Bytes > r.Extensions[j].Bytes
+		}
+		return r.Extensions[i].Ext < r.Extensions[j].Ext
+	})
+	if b.top > 0 {
+		r.Largest = sortTop(b.largest, b.top, func(x, y fileEntry) bool { return x.Size > y.Size })
+		r.Oldest = sortTop(b.oldest, b.top, func(x, y fileEntry) bool { return x.ModTime.Before(y.ModTime) })
+	}
+	return r
+}
+
+// writeReport 按输出格式输出报告（text 为终端表格，json 为结构化数据）
+func writeReport(format string, w io.Writer, opts scanOptions, r fileReport, errs []jsonError, summary scanSummary) error {
+	if format == "json" {
+		doc := struct {
+			SchemaVersion int          `json:"schema_version"`
+			Params        jsonParams   `json:"params"`
+			Extensions    []extStat    `json:"extensions"`
+			Buckets       []bucketStat `json:"size_buckets"`
+			Largest       []jsonFile   `json:"largest"`
+			Oldest        []jsonFile   `json:"oldest"`
+			Errors        []jsonError  `json:"errors"`
+			Summary       jsonSummary  `json:"summary"`
+		}{
+			SchemaVersion: schemaVersion,
+			Params:        toJSONParams(opts),
+			Extensions:    r.Extensions,
+			Buckets:       r.Buckets,
+			Largest:       []jsonFile{},
+			Oldest:        []jsonFile{},
+			Errors:        errs,
+			Summary:       toJSONSummary(summary),
+		}
+		if doc.Extensions == nil {
+			doc.Extensions = []extStat{}
+		}
+		if doc.Errors == nil {
+			doc.Errors = []jsonError{}
+		}
+		for _, f := range r.Largest {
+			doc.Largest = append(doc.Largest, toJSONFile(f))
+		}
+		for _, f := range r.Oldest {
+			doc.Oldest = append(doc.Oldest, toJSONFile(f))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false) // 区间名称含 < 和 >，不转义
+		return enc.Encode(doc)
+	}
+
+	printErrors(errs)
+
+	fmt.Fprintln(w, "按扩展名统计：")
+	t := table.New("扩展名", "文件数", "总大小", "占比").AlignRight(1, 2, 3)
+	for _, s := range r.Extensions {
+		t.Row(s.Ext, strconv.Itoa(s.Count), formatSize(s.Bytes), percent(s.Bytes, summary.TotalBytes))
+	}
+	t.Render(w)
+
+	fmt.Fprintln(w, "\n大小分布：")
+	t = table.New("区间", "文件数", "总大小").AlignRight(1, 2)
+	for _, b := range r.Buckets {
+		t.Row(b.Label, strconv.Itoa(b.Count), formatSize(b.Bytes))
+	}
+	t.Render(w)
+
+	if len(r.Largest) > 0 {
+		fmt.Fprintf(w, "\n最大的 %d 个文件：\n", len(r.Largest))
+		t = table.New("大小", "修改时间", "路径").AlignRight(0)
+		for _, f := range r.Largest {
+			t.Row(formatSize(f.Size), f.ModTime.Format("2006-01-02 15:04"), f.Path)
+		}
+		t.Render(w)
+
+		fmt.Fprintf(w, "\n最旧的 %d 个文件：\n", len(r.Oldest))
+		t = table.New("修改时间", "大小", "路径").AlignRight(1)
+		for _, f := range r.Oldest {
+			t.Row(f.ModTime.Format("2006-01-02 15:04"), formatSize(f.Size), f.Path)
+		}
+		t.Render(w)
+	}
+
+	fmt.Fprintln(w, "----")
+	fmt.Fprintf(w, "扫描文件: %d\n", summary.Scanned)
+	fmt.Fprintf(w, "匹配文件: %d\n", summary.Matched)
+	fmt.Fprintf(w, "总大小: %d字节（%s）\n", summary.TotalBytes, formatSize(summary.TotalBytes))
+	fmt.Fprintf(w, "错误数: %d\n", summary.Errors)
+	return printCanceled(w, summary)
+}
+
+// percent 计算占比，如 12.5%
+func percent(part, total int64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
+)
+
+// scanOptions 扫描参数（由命令行参数转换而来）
+type scanOptions struct {
+	Root      string    // 检查路径
+	Recursive bool      // 是否递归子目录
+	MinSize   int64     // 最小文件大小（字节）
+	MaxSize   int64     // 最大文件大小（字节），0 表示不限制
+	NewerThan time.Time // 只保留在此之后修改的文件，零值表示不限制
+	OlderThan time.Time // 只保留在此之前修改的文件，零值表示不限制
+	Exts      []string  // 扩展名过滤（已统一为小写、带点）
+
+	MIMEs        []string // 内容类型过滤（按文件头检测），如 image/*、application/pdf
+	MismatchOnly bool     // 只保留扩展名与内容类型不符的文件
+
+	Include    []string          // --include 通配符（原样保存，用于输出参数）
+	Exclude    []string          // --exclude 通配符
+	IgnoreFile string            // --ignore-file 路径
+	Filter     *pathmatch.Filter // 由以上三项编译而来，为 nil 时不过滤
+
+	Workers int // 并行读取目录的 goroutine 数量
+}
+
+// detectContent 是否需要读取文件头检测内容类型（较慢，只在需要时进行）
+func (o scanOptions) detectContent() bool {
+	return len(o.MIMEs) > 0 || o.MismatchOnly
+}
+
+// fileEntry 一个匹配的文件
+type fileEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+
+	MIME     string // 检测到的内容类型，未检测时为空
+	Mismatch bool   // 扩展名与内容类型不符
+}
+
+// scanSummary 扫描汇总
+type scanSummary struct {
+	Scanned    int   // 扫描的普通文件数
+	Matched    int   // 匹配的文件数
+	TotalBytes int64 // 匹配文件的总大小
+	Errors     int   // 遇到的错误数（如权限不足）
+	Mismatched int   // 匹配文件中扩展名与内容不符的数量（仅检测内容类型时统计）
+	Canceled   bool  // 扫描被中断（如 Ctrl-C），以上统计不完整
+}
+
+// normalizeExts 统一扩展名格式：小写并带前导点，如 "JPG" -> ".jpg"
+func normalizeExts(exts []string) []string {
+	result := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		result = append(result, ext)
+	}
+	return result
+}
+
+// matchExt 判断文件扩展名是否在过滤列表中（列表为空时全部匹配）
+func matchExt(path string, exts []string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// matchInfo 判断文件是否满足大小、修改时间和扩展名条件（路径通配符由 Filter 负责）
+func (o scanOptions) matchInfo(path string, info fs.FileInfo) bool {
+	if info.Size() < o.MinSize || (o.MaxSize > 0 && info.Size() > o.MaxSize) {
+		return false
+	}
+	if !o.NewerThan.IsZero() && !info.ModTime().After(o.NewerThan) {
+		return false
+	}
+	if !o.OlderThan.IsZero() && !info.ModTime().Before(o.OlderThan) {
+		return false
+	}
+	return matchExt(path, o.Exts)
+}
+
+// relPath 返回相对扫描根目录、以 / 分隔的路径，用于通配符匹配
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		// 根目录本身是文件时，按文件名匹配
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// scan 用 opts.Workers 个 goroutine 并行读取目录，对每个匹配的文件调用 onMatch，对每个错误调用 onError。
+// 回调在锁内调用，调用方无需处理并发；文件的回调顺序不固定。
+// 无法读取的目录或文件只会被记录并跳过，不会中断整个扫描；ctx 取消时尽快返回已完成部分的汇总。
+func scan(ctx context.Context, opts scanOptions, onMatch func(fileEntry), onError func(path string, err error)) scanSummary {
+	s := &scanner{ctx: ctx, opts: opts, onMatch: onMatch, onError: onError}
+
+	// 根路径是指向目录的符号链接时也要扫描，所以用 Stat；目录中的条目仍按 Lstat，不跟随符号链接
+	info, err := os.Stat(opts.Root)
+	if err != nil {
+		s.error(opts.Root, err)
+		return s.summary
+	}
+	if !info.IsDir() {
+		s.file(opts.Root, fs.FileInfoToDirEntry(info))
+		return s.summary
+	}
+
+	workers := max(opts.Workers, 1)
+	s.dirs = make(chan string, workers*64)
+	s.pending.Add(1)
+	s.dirs <- opts.Root
+	for i := 0; i < workers; i++ {
+		go func() {
+			for dir := range s.dirs {
+				s.walkDir(dir)
+				s.pending.Done()
+			}
+		}()
+	}
+	s.pending.Wait()
+	close(s.dirs)
+
+	s.summary.Canceled = ctx.Err() != nil
+	return s.summary
+}
+
+// scanner 一次扫描的共享状态
+type scanner struct {
+	ctx     context.Context
+	opts    scanOptions
+	dirs    chan string    // 待读取的目录
+	pending sync.WaitGroup // 已入队但未处理完的目录数
+
+	mu      sync.Mutex // 保护 summary 和回调
+	summary scanSummary
+	onMatch func(fileEntry)
+	onError func(string, error)
+}
+
+func (s *scanner) error(path string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary.Errors++
+	s.onError(path, err)
+}
+
+// walkDir 读取一个目录：子目录放入队列，文件直接检查
+func (s *scanner) walkDir(dir string) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		s.error(dir, err) // 仍然处理出错前读到的条目
+	}
+
+	for _, d := range entries {
+		if s.ctx.Err() != nil {
+			return
+		}
+		path := filepath.Join(dir, d.Name())
+		if !d.IsDir() {
+			s.file(path, d)
+			continue
+		}
+
+		// 非递归模式下只检查根目录本身；被排除或忽略的目录直接跳过，不再进入
+		if !s.opts.Recursive || s.opts.Filter.SkipDir(relPath(s.opts.Root, path)) {
+			continue
+		}
+		s.pending.Add(1)
+		select {
+		case s.dirs <- path:
+		default:
+			// 队列已满时就地处理，避免所有 worker 都阻塞在入队上
+			s.walkDir(path)
+			s.pending.Done()
+		}
+	}
+}
+
+// file 检查一个非目录条目，过滤和内容检测在锁外进行，可以并行
+func (s *scanner) file(path string, d fs.DirEntry) {
+	if !s.opts.Filter.MatchFile(relPath(s.opts.Root, path)) {
+		return
+	}
+
+	// 只统计普通文件（跳过符号链接、设备文件等）
+	if !d.Type().IsRegular() {
+		return
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		s.error(path, err)
+		return
+	}
+
+	matched := s.opts.matchInfo(path, info)
+	f := fileEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	if matched && s.opts.detectContent() {
+		f.MIME, f.Mismatch, matched, err = s.opts.matchContent(path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary.Scanned++
+	if err != nil {
+		s.summary.Errors++
+		s.onError(path, err)
+		return
+	}
+	if !matched {
+		return
+	}
+	if f.Mismatch {
+		s.summary.Mismatched++
+	}
+	s.summary.Matched++
+	s.summary.TotalBytes += info.Size()
+	s.onMatch(f)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreFileName 回收站子目录中的恢复清单，每行一个 restoreEntry
+const restoreFileName = "restore.jsonl"
+
+// restoreEntry 一个被移到回收站的文件
+type restoreEntry struct {
+	Original string    `json:"original"` // 原位置（绝对路径）
+	Trashed  string    `json:"trashed"`  // 回收站中的位置（绝对路径）
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Time     time.Time `json:"time"` // 移入回收站的时间
+}
+
+// trashRun 一次运行对应的回收站子目录：files/ 下保留原目录层级，恢复清单逐条追加，
+// 中途中断也不会丢失已移动文件的记录
+type trashRun struct {
+	dir string
+	f   *os.File
+	enc *json.Encoder
+}
+
+func newTrashRun(root, run string) (*trashRun, error) {
+	dir, err := filepath.Abs(filepath.Join(root, run))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, restoreFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &trashRun{dir: dir, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Put 把文件移到回收站并记录到恢复清单，返回回收站中的路径
+func (t *trashRun) Put(root string, f fileEntry) (string, error) {
+	dest := filepath.Join(t.dir, "files", filepath.FromSlash(relPath(root, f.Path)))
+	if err := moveFile(f.Path, dest); err != nil {
+		return "", err
+	}
+	err := t.enc.Encode(restoreEntry{
+		Original: absOrSelf(f.Path),
+		Trashed:  dest,
+		Size:     f.Size,
+		ModTime:  f.ModTime,
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return dest, fmt.Errorf("已移到回收站，但写入恢复清单失败: %w", err)
+	}
+	return dest, nil
+}
+
+func (t *trashRun) Close() error {
+	return t.f.Close()
+}
+
+// readRestoreEntries 读取回收站子目录中的恢复清单
+func readRestoreEntries(dir string) ([]restoreEntry, error) {
+	f, err := os.Open(filepath.Join(dir, restoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []restoreEntry
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		var e restoreEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", restoreFileName, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// newRestoreCmd 把 --action trash 移走的文件放回原位置
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "restore TRASH_RUN_DIR",
+		Short:        "从回收站恢复 --action trash 移走的文件",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			logPath, _ := cmd.Flags().GetString("audit-log")
+
+			entries, err := readRestoreEntries(args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !yes {
+				for _, e := range entries {
+					fmt.Fprintf(out, "[预演] 恢复 %s -> %s\n", e.Trashed, e.Original)
+				}
+				fmt.Fprintf(out, "----\n共 %d 个文件将被恢复，未做任何修改，确认无误后加 --yes 执行\n", len(entries))
+				return nil
+			}
+
+			log, err := openActionLog(logPath, newRunID())
+			if err != nil {
+				return err
+			}
+			defer log.Close()
+
+			done, failed := 0, 0
+			for _, e := range entries {
+				if cmd.Context().Err() != nil {
+					return errInterrupted
+				}
+				err := restoreFile(e)
+				if logErr := log.Write("restore", e.Trashed, e.Original, err); logErr != nil {
+					return fmt.Errorf("写入操作日志失败，已停止: %w", logErr)
+				}
+				if err != nil {
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "恢复 %s 失败: %v\n", e.Original, err)
+					continue
+				}
+				done++
+				fmt.Fprintf(out, "已恢复 %s\n", e.Original)
+			}
+			fmt.Fprintf(out, "----\n成功: %d\n失败: %d\n", done, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d 个文件恢复失败", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("yes", "y", false, "确认执行（默认只预演）")
+	cmd.Flags().String("audit-log", filepath.Join(dataDir(), "actions.log"), "操作日志文件")
+	return cmd
+}
+
+// restoreFile 把文件移回原位置；原位置已有文件或回收站中的文件已不存在时报错
+func restoreFile(e restoreEntry) error {
+	if _, err := os.Lstat(e.Trashed); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("回收站中已没有该文件（可能已恢复）")
+	}
+	return moveFile(e.Trashed, e.Original)
+}
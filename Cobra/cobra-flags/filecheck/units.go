@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sizeUnits 支持的大小单位：KB/MB 等为十进制，KiB/MiB 等以及单字母 k/m/g 为二进制
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// parseSize 解析带单位的大小，如 1024、10MB、1.5GiB、512k
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && !unicode.IsDigit(r) })
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	mult, ok := sizeUnits[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("无效的大小 %q，示例：1024、512k、10MB、1.5GiB", s)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的大小 %q，示例：1024、512k、10MB、1.5GiB", s)
+	}
+	// float64(math.MaxInt64) 即 2^63，等于它时转换也会溢出
+	v := n * float64(mult)
+	if v >= math.MaxInt64 {
+		return 0, fmt.Errorf("大小 %q 超出范围（不能超过 8388607TiB）", s)
+	}
+	return int64(v), nil
+}
+
+// formatSize 将字节数格式化为二进制单位，如 1.5MiB
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	// 保留两位小数并去掉多余的 0，如 1.50 -> 1.5、2.00 -> 2
+	num := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", float64(n)/float64(div)), "0"), ".")
+	return num + string("KMGTPE"[exp]) + "iB"
+}
+
+// sizeValue 实现 pflag.Value，让大小参数接受单位
+type sizeValue int64
+
+func newSizeValue(def int64) *sizeValue {
+	v := sizeValue(def)
+	return &v
+}
+
+func (v *sizeValue) String() string {
+	if *v == 0 {
+		return "0"
+	}
+	return formatSize(int64(*v))
+}
+
+func (v *sizeValue) Set(s string) error {
+	n, err := parseSize(s)
+	if err != nil {
+		return err
+	}
+	*v = sizeValue(n)
+	return nil
+}
+
+// Type 显示在 --help 中，如 --min-size size
+func (v *sizeValue) Type() string {
+	return "size"
+}
+
+// dateLayouts 支持的绝对时间格式
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+// parseAge 解析相对时长（72h、30d、2w、90m）或绝对时间（2024-01-31），返回对应的时间点
+func parseAge(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// time.ParseDuration 不支持天和周，单独处理
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		days, err := strconv.ParseFloat(s[:n-1], 64)
+		if err == nil && days >= 0 {
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.Add(-time.Duration(days * float64(24*time.Hour))), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("无效的时间 %q，示例：72h、30d、2w、2024-01-31、2024-01-31 08:00", s)
+}
+
+// ageValue 实现 pflag.Value：解析时即换算成绝对时间点
+type ageValue struct {
+	raw string
+	t   time.Time
+}
+
+func (v *ageValue) String() string {
+	return v.raw
+}
+
+func (v *ageValue) Set(s string) error {
+	t, err := parseAge(s, time.Now())
+	if err != nil {
+		return err
+	}
+	v.raw, v.t = s, t
+	return nil
+}
+
+// Type 显示在 --help 中，如 --newer-than time
+func (v *ageValue) Type() string {
+	return "time"
+}
+
+// Time 未设置时返回零值
+func (v *ageValue) Time() time.Time {
+	return v.t
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// watchEvent 监控模式下输出的一个文件变化
+type watchEvent struct {
+	Kind    string    // created | modified | deleted | renamed
+	Path    string    // 文件路径（重命名时为新路径）
+	OldPath string    // 重命名前的路径
+	Size    int64     // 删除事件没有大小
+	ModTime time.Time // 删除事件没有修改时间
+	Time    time.Time // 事件输出时间
+}
+
+// eventKindNames 事件类型的中文名称（文本输出使用）
+var eventKindNames = map[string]string{
+	"created":  "新建",
+	"modified": "修改",
+	"deleted":  "删除",
+	"renamed":  "重命名",
+}
+
+// eventWriter 监控事件的输出方式
+type eventWriter interface {
+	Event(ev watchEvent)
+	Error(path string, err error)
+}
+
+// newEventWriter 按格式创建事件输出器（监控模式只支持 text 和 ndjson）
+func newEventWriter(format string, w io.Writer) eventWriter {
+	if format == "ndjson" {
+		return &ndjsonEventWriter{enc: json.NewEncoder(w)}
+	}
+	return &textEventWriter{w: w}
+}
+
+// validWatchOutput 监控模式需要流式输出，json 和 csv 不适用
+func validWatchOutput(format string) error {
+	if format != "text" && format != "ndjson" {
+		return fmt.Errorf("--watch 只支持 text 或 ndjson 输出，当前为 %q", format)
+	}
+	return nil
+}
+
+type textEventWriter struct {
+	w io.Writer
+}
+
+func (t *textEventWriter) Event(ev watchEvent) {
+	ts := ev.Time.Format("2006-01-02 15:04:05")
+	name := eventKindNames[ev.Kind]
+	switch ev.Kind {
+	case "deleted":
+		fmt.Fprintf(t.w, "%s %s %s\n", ts, name, ev.Path)
+	case "renamed":
+		fmt.Fprintf(t.w, "%s %s %s -> %s\t%d字节\n", ts, name, ev.OldPath, ev.Path, ev.Size)
+	default:
+		fmt.Fprintf(t.w, "%s %s %s\t%d字节\n", ts, name, ev.Path, ev.Size)
+	}
+}
+
+func (t *textEventWriter) Error(path string, err error) {
+	fmt.Fprintf(os.Stderr, "跳过 %s: %v\n", path, err)
+}
+
+type ndjsonEventWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonEventWriter) Event(ev watchEvent) {
+	rec := struct {
+		Type    string     `json:"type"`
+		Event   string     `json:"event"`
+		Path    string     `json:"path"`
+		OldPath string     `json:"old_path,omitempty"`
+		Size    *int64     `json:"size,omitempty"`
+		ModTime *time.Time `json:"mod_time,omitempty"`
+		Time    time.Time  `json:"time"`
+	}{Type: "event", Event: ev.Kind, Path: ev.Path, OldPath: ev.OldPath, Time: ev.Time}
+	if ev.Kind != "deleted" {
+		rec.Size, rec.ModTime = &ev.Size, &ev.ModTime
+	}
+	n.enc.Encode(rec)
+}
+
+func (n *ndjsonEventWriter) Error(path string, err error) {
+	n.enc.Encode(struct {
+		Type string `json:"type"`
+		jsonError
+	}{"error", jsonError{Path: path, Error: err.Error()}})
+}
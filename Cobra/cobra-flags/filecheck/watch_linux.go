@@ -0,0 +1,323 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchMask 关注的 inotify 事件
+const watchMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// pendingEvent 等待去抖输出的事件
+type pendingEvent struct {
+	kind     string // created | modified | deleted | renamed
+	oldPath  string // 仅 renamed
+	deadline time.Time
+}
+
+// inotifyWatcher 基于 inotify 的目录监控
+type inotifyWatcher struct {
+	opts     scanOptions
+	debounce time.Duration
+	out      eventWriter
+
+	fd      int
+	dirs    map[int]string          // wd -> 目录路径
+	pending map[string]pendingEvent // 路径 -> 待输出事件
+	moves   map[uint32]string       // rename cookie -> 原路径
+
+	// file 根路径是文件时为该文件，只监控其所在目录中这个文件的变化
+	file string
+
+	// dirMoves 移出的目录（rename cookie -> 原路径）。一批事件处理完仍未配对的，是移出了监控范围
+	dirMoves map[uint32]string
+}
+
+// checkWatchSupported Linux 支持监控模式
+func checkWatchSupported() error {
+	return nil
+}
+
+// watch 持续监控 opts.Root，直到 ctx 被取消
+func watch(ctx context.Context, opts scanOptions, debounce time.Duration, out eventWriter) error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("初始化 inotify 失败: %w", err)
+	}
+	defer unix.Close(fd)
+
+	w := &inotifyWatcher{
+		opts:     opts,
+		debounce: debounce,
+		out:      out,
+		fd:       fd,
+		dirs:     make(map[int]string),
+		pending:  make(map[string]pendingEvent),
+		moves:    make(map[uint32]string),
+		dirMoves: make(map[uint32]string),
+	}
+	// inotify 对文件本身的监控在文件被替换（编辑器先写临时文件再改名）后失效，所以监控其所在目录
+	if info, err := os.Stat(opts.Root); err == nil && !info.IsDir() {
+		w.file = filepath.Clean(opts.Root)
+		dir := filepath.Dir(w.file)
+		wd, err := unix.InotifyAddWatch(fd, dir, watchMask)
+		if err != nil {
+			return fmt.Errorf("监控 %s 失败: %w", dir, err)
+		}
+		w.dirs[wd] = dir
+	} else if err := w.addDir(opts.Root, false); err != nil {
+		return err
+	}
+	return w.loop(ctx)
+}
+
+// addDir 监控目录；递归模式下同时监控其子目录。
+// reportExisting 为 true 时把目录中已有的文件作为新建事件（用于监控建立前就写入的文件）。
+func (w *inotifyWatcher) addDir(dir string, reportExisting bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			w.out.Error(path, err)
+			return nil
+		}
+		if !d.IsDir() {
+			if reportExisting {
+				w.queue(path, "created", "")
+			}
+			return nil
+		}
+		// 根目录总是监控；子目录只在递归模式下且未被排除时监控
+		if path != w.opts.Root && (!w.opts.Recursive || w.opts.Filter.SkipDir(relPath(w.opts.Root, path))) {
+			return filepath.SkipDir
+		}
+
+		wd, err := unix.InotifyAddWatch(w.fd, path, watchMask)
+		if err != nil {
+			w.out.Error(path, fmt.Errorf("添加监控失败: %w", err))
+			return filepath.SkipDir
+		}
+		w.dirs[wd] = path
+		return nil
+	})
+}
+
+// loop 读取事件并按去抖间隔输出
+func (w *inotifyWatcher) loop(ctx context.Context) error {
+	buf := make([]byte, 64*1024)
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.flush(time.Time{}) // 退出前输出所有待处理事件
+			return nil
+		default:
+		}
+
+		// 定时唤醒，以便检查取消和输出到期的事件
+		n, err := unix.Poll(fds, 100)
+		if err != nil && err != unix.EINTR {
+			return err
+		}
+		if n > 0 {
+			if err := w.read(buf); err != nil {
+				return err
+			}
+		}
+		w.flush(time.Now())
+	}
+}
+
+// read 解析一批 inotify 事件
+func (w *inotifyWatcher) read(buf []byte) error {
+	n, err := unix.Read(w.fd, buf)
+	if err == unix.EAGAIN || err == unix.EINTR {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取 inotify 事件失败: %w", err)
+	}
+
+	for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+		ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameBytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+int(ev.Len)]
+		name := string(bytes.TrimRight(nameBytes, "\x00"))
+		offset += unix.SizeofInotifyEvent + int(ev.Len)
+
+		if ev.Mask&unix.IN_Q_OVERFLOW != 0 {
+			w.out.Error(w.opts.Root, fmt.Errorf("事件队列溢出，部分事件已丢失"))
+			continue
+		}
+		if ev.Mask&unix.IN_IGNORED != 0 {
+			delete(w.dirs, int(ev.Wd))
+			continue
+		}
+		dir, ok := w.dirs[int(ev.Wd)]
+		if !ok || name == "" {
+			continue
+		}
+		w.handle(filepath.Join(dir, name), ev.Mask, ev.Cookie)
+	}
+
+	// 同一次重命名的 MOVED_FROM 和 MOVED_TO 在队列中相邻，读完一批仍未配对的目录已移出监控范围，
+	// 不再监控，否则其中的变化会以原来的路径输出
+	for _, old := range w.dirMoves {
+		w.removeDir(old)
+	}
+	clear(w.dirMoves)
+	return nil
+}
+
+// handle 将单个 inotify 事件转换为待输出事件
+func (w *inotifyWatcher) handle(path string, mask, cookie uint32) {
+	isDir := mask&unix.IN_ISDIR != 0
+
+	if isDir {
+		if w.file != "" {
+			return
+		}
+		switch {
+		case mask&unix.IN_MOVED_FROM != 0:
+			w.dirMoves[cookie] = path
+		case mask&unix.IN_MOVED_TO != 0:
+			// 监控范围内的目录重命名：已有的监控继续有效，只需更新路径
+			if old, ok := w.dirMoves[cookie]; ok {
+				delete(w.dirMoves, cookie)
+				w.renameDir(old, path)
+				return
+			}
+			fallthrough
+		case mask&unix.IN_CREATE != 0:
+			// 递归模式下，新建或移入的目录需要加入监控
+			if w.opts.Recursive {
+				w.addDir(path, true)
+			}
+		}
+		return
+	}
+
+	switch {
+	case mask&unix.IN_CREATE != 0:
+		w.queue(path, "created", "")
+	case mask&(unix.IN_MODIFY|unix.IN_CLOSE_WRITE) != 0:
+		w.queue(path, "modified", "")
+	case mask&unix.IN_DELETE != 0:
+		w.queue(path, "deleted", "")
+	case mask&unix.IN_MOVED_FROM != 0:
+		// 先记为删除；若随后收到同 cookie 的 MOVED_TO 则改为重命名
+		w.moves[cookie] = path
+		w.queue(path, "deleted", "")
+	case mask&unix.IN_MOVED_TO != 0:
+		if old, ok := w.moves[cookie]; ok {
+			delete(w.moves, cookie)
+			delete(w.pending, old)
+			w.queue(path, "renamed", old)
+		} else {
+			w.queue(path, "created", "") // 从监控范围外移入
+		}
+	}
+}
+
+// renameDir 目录重命名后，更新它及其子目录对应的监控路径
+func (w *inotifyWatcher) renameDir(oldPath, newPath string) {
+	prefix := oldPath + string(filepath.Separator)
+	for wd, p := range w.dirs {
+		if p == oldPath || strings.HasPrefix(p, prefix) {
+			w.dirs[wd] = newPath + p[len(oldPath):]
+		}
+	}
+}
+
+// removeDir 取消目录及其子目录的监控
+func (w *inotifyWatcher) removeDir(dir string) {
+	prefix := dir + string(filepath.Separator)
+	for wd, p := range w.dirs {
+		if p == dir || strings.HasPrefix(p, prefix) {
+			unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+}
+
+// queue 合并同一路径在去抖间隔内的多次事件
+func (w *inotifyWatcher) queue(path, kind, oldPath string) {
+	prev, exists := w.pending[path]
+	if exists {
+		switch {
+		case prev.kind == "created" && kind == "deleted":
+			delete(w.pending, path) // 创建后立即删除，视为没有发生
+			return
+		case prev.kind == "created" || prev.kind == "renamed":
+			kind, oldPath = prev.kind, prev.oldPath // 后续修改并入创建/重命名
+		case prev.kind == "deleted" && kind == "created":
+			kind = "modified" // 删除后重建（如编辑器保存），视为修改
+		}
+	}
+	w.pending[path] = pendingEvent{kind: kind, oldPath: oldPath, deadline: time.Now().Add(w.debounce)}
+}
+
+// flush 输出到期的事件；now 为零值时输出全部
+func (w *inotifyWatcher) flush(now time.Time) {
+	for path, ev := range w.pending {
+		if !now.IsZero() && now.Before(ev.deadline) {
+			continue
+		}
+		delete(w.pending, path)
+		w.emit(path, ev)
+	}
+	// 超时未配对的移出事件已按删除处理，清理 cookie
+	if len(w.pending) == 0 {
+		clear(w.moves)
+	}
+}
+
+// emit 按过滤条件输出事件
+func (w *inotifyWatcher) emit(path string, ev pendingEvent) {
+	if w.file != "" && path != w.file {
+		return
+	}
+	rel := relPath(w.opts.Root, path)
+	if !w.opts.Filter.MatchFile(rel) {
+		return
+	}
+
+	if ev.kind == "deleted" {
+		// 文件已不存在，只能按扩展名过滤
+		if matchExt(path, w.opts.Exts) {
+			w.out.Event(watchEvent{Kind: ev.kind, Path: path, Time: time.Now()})
+		}
+		return
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return // 事件输出前文件已被删除或移走
+	}
+	if !info.Mode().IsRegular() || !w.opts.matchInfo(path, info) {
+		return
+	}
+	if w.opts.detectContent() {
+		if _, _, ok, err := w.opts.matchContent(path); err != nil || !ok {
+			return
+		}
+	}
+	w.out.Event(watchEvent{
+		Kind:    ev.kind,
+		Path:    path,
+		OldPath: ev.oldPath,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Time:    time.Now(),
+	})
+}
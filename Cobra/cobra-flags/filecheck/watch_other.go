@@ -0,0 +1,21 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errWatchUnsupported 监控模式依赖 Linux inotify，其他平台不支持
+var errWatchUnsupported = errors.New("--watch 仅支持 Linux")
+
+// checkWatchSupported 在 PreRunE 中调用，避免做完初始扫描才报错
+func checkWatchSupported() error {
+	return errWatchUnsupported
+}
+
+func watch(ctx context.Context, opts scanOptions, debounce time.Duration, out eventWriter) error {
+	return errWatchUnsupported
+}
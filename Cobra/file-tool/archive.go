@@ -0,0 +1,620 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// archiveFormats 支持的归档格式
+var archiveFormats = []string{"tar", "tar.gz", "zip"}
+
+// archiveResult 归档命令的输出
+type archiveResult struct {
+	Archive string   `json:"archive"`
+	Format  string   `json:"format"`
+	Entries []string `json:"entries"`
+}
+
+// newArchiveCmd 归档命令：create / extract
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "创建和解压 tar、tar.gz、zip 归档",
+	}
+	cmd.PersistentFlags().StringP("format", "f", "", "归档格式（tar|tar.gz|zip），默认按文件扩展名判断")
+	cmd.PersistentFlags().BoolP("verbose", "v", false, "输出处理的每个条目")
+
+	cmd.AddCommand(newArchiveCreateCmd())
+	cmd.AddCommand(newArchiveExtractCmd())
+	return cmd
+}
+
+func newArchiveCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create ARCHIVE PATH...",
+		Short: "创建归档",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			include, _ := cmd.Flags().GetStringSlice("include")
+			exclude, _ := cmd.Flags().GetStringSlice("exclude")
+			if err := validPatterns(append(include, exclude...)); err != nil {
+				return err
+			}
+			format, err := archiveFormat(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			entries, err := createArchive(w, args[0], format, args[1:], include, exclude)
+			if err != nil {
+				return err
+			}
+			if err := printArchiveResult(cmd, g, archiveResult{args[0], format, entries}, "已归档"); err != nil {
+				return err
+			}
+			return w.Err()
+		},
+	}
+	cmd.Flags().StringSlice("include", nil, "只归档匹配的文件（通配符，匹配相对路径或文件名，可多个）")
+	cmd.Flags().StringSlice("exclude", nil, "排除匹配的文件或目录（通配符，可多个）")
+	return cmd
+}
+
+func newArchiveExtractCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "extract ARCHIVE",
+		Short: "解压归档（拒绝越出目标目录的条目）",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			dest, _ := cmd.Flags().GetString("dir")
+			strip, _ := cmd.Flags().GetInt("strip-components")
+			if strip < 0 {
+				return fmt.Errorf("--strip-components 不能为负数")
+			}
+			format, err := archiveFormat(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			entries, err := extractArchive(args[0], format, dest, strip)
+			if err != nil {
+				return err
+			}
+			return printArchiveResult(cmd, g, archiveResult{args[0], format, entries}, "已解压")
+		},
+	}
+	cmd.Flags().StringP("dir", "C", ".", "解压到的目录")
+	cmd.Flags().Int("strip-components", 0, "去掉条目路径的前 N 级目录")
+	return cmd
+}
+
+// printArchiveResult 输出归档结果
+func printArchiveResult(cmd *cobra.Command, g globalOptions, r archiveResult, verb string) error {
+	if r.Entries == nil {
+		r.Entries = []string{}
+	}
+	if g.Output == "json" {
+		return writeJSON(cmd.OutOrStdout(), r)
+	}
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	if verbose {
+		for _, e := range r.Entries {
+			fmt.Fprintln(cmd.OutOrStdout(), e)
+		}
+	}
+	_, err := fmt.Fprintf(cmd.OutOrStdout(), "%s %d 个条目（%s，%s）\n", verb, len(r.Entries), r.Archive, r.Format)
+	return err
+}
+
+// archiveFormat 优先使用 --format，否则按扩展名判断
+func archiveFormat(cmd *cobra.Command, name string) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+			format = "tar.gz"
+		case strings.HasSuffix(lower, ".tar"):
+			format = "tar"
+		case strings.HasSuffix(lower, ".zip"):
+			format = "zip"
+		default:
+			return "", fmt.Errorf("无法从文件名 %q 判断归档格式，请使用 --format", name)
+		}
+	}
+	for _, f := range archiveFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("归档格式必须是 tar、tar.gz 或 zip，当前为 %q", format)
+}
+
+// validPatterns 校验通配符语法
+func validPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("无效的通配符 %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// matchAny 任一通配符匹配相对路径或文件名即视为匹配
+func matchAny(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveWriter 屏蔽 tar 和 zip 写入的差异
+type archiveWriter interface {
+	// Add 写入一个条目；name 使用 / 分隔，link 为符号链接目标
+	Add(name string, info fs.FileInfo, link string, content io.Reader) error
+	Close() error
+}
+
+// createArchive 将 paths 打包到 archive，条目名以各路径的最后一级为前缀（与 tar 一致）
+func createArchive(w *walker, archive, format string, paths, include, exclude []string) (entries []string, err error) {
+	absArchive, _ := filepath.Abs(archive)
+
+	f, err := os.Create(archive)
+	if err != nil {
+		return nil, err
+	}
+	aw := newArchiveWriter(f, format)
+	defer func() {
+		if cerr := aw.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(archive) // 失败时不留下不完整的归档
+		}
+	}()
+
+	for _, root := range paths {
+		root = filepath.Clean(root)
+		prefix := filepath.Base(root)
+		if prefix == "." || prefix == string(filepath.Separator) {
+			prefix = ""
+		}
+
+		err = w.Walk(root, func(p string, info fs.FileInfo, depth int) error {
+			rel, _ := filepath.Rel(root, p)
+			name := filepath.ToSlash(filepath.Join(prefix, rel))
+			if name == "." {
+				return nil
+			}
+			if abs, _ := filepath.Abs(p); abs == absArchive {
+				return nil // 不把正在写入的归档自己打包进去
+			}
+
+			if len(exclude) > 0 && matchAny(exclude, name) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				// 指定 --include 时只写入匹配的文件，目录在解压时自动创建
+				if len(include) > 0 {
+					return nil
+				}
+				entries = append(entries, name+"/")
+				return aw.Add(name+"/", info, "", nil)
+			}
+			if len(include) > 0 && !matchAny(include, name) {
+				return nil
+			}
+
+			switch {
+			case info.Mode()&fs.ModeSymlink != 0:
+				link, err := os.Readlink(p)
+				if err != nil {
+					w.report(p, err)
+					return nil
+				}
+				entries = append(entries, name)
+				return aw.Add(name, info, link, nil)
+			case info.Mode().IsRegular():
+				src, err := os.Open(p)
+				if err != nil {
+					w.report(p, err)
+					return nil
+				}
+				defer src.Close()
+				entries = append(entries, name)
+				return aw.Add(name, info, "", src)
+			default:
+				w.report(p, fmt.Errorf("不支持归档的文件类型 %s", fileType(info.Mode())))
+				return nil
+			}
+		})
+		if err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+func newArchiveWriter(w io.Writer, format string) archiveWriter {
+	switch format {
+	case "zip":
+		return &zipWriter{zw: zip.NewWriter(w)}
+	case "tar.gz":
+		gz := gzip.NewWriter(w)
+		return &tarWriter{tw: tar.NewWriter(gz), gz: gz}
+	default:
+		return &tarWriter{tw: tar.NewWriter(w)}
+	}
+}
+
+type tarWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer // 仅 tar.gz 时非空
+}
+
+func (t *tarWriter) Add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if content != nil {
+		_, err = io.Copy(t.tw, content)
+	}
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	err := t.tw.Close()
+	if t.gz != nil {
+		if gerr := t.gz.Close(); err == nil {
+			err = gerr
+		}
+	}
+	return err
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) Add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if !info.IsDir() {
+		hdr.Method = zip.Deflate
+	}
+	fw, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	// zip 中符号链接的内容就是链接目标（Unix 约定）
+	if link != "" {
+		_, err = io.WriteString(fw, link)
+		return err
+	}
+	if content != nil {
+		_, err = io.Copy(fw, content)
+	}
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+// archiveEntry 从归档中读出的一个条目
+type archiveEntry struct {
+	Name    string
+	Mode    fs.FileMode
+	ModTime time.Time
+	Link    string // 符号链接或硬链接的目标
+	Hard    bool   // 是否为硬链接（仅 tar）
+	Open    func() (io.ReadCloser, error)
+}
+
+// extractArchive 解压归档到 dest。任何越出 dest 的条目都会使解压失败。
+func extractArchive(archive, format, dest string, strip int) ([]string, error) {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return nil, err
+	}
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	handle := func(e archiveEntry) error {
+		name, ok := stripComponents(e.Name, strip)
+		if !ok {
+			return nil
+		}
+		e.Name = name
+		// 硬链接的目标也是归档内路径，要去掉同样的层级
+		if e.Hard {
+			link, ok := stripComponents(e.Link, strip)
+			if !ok {
+				return fmt.Errorf("%s: 硬链接目标 %s 被 --strip-components 去掉了", name, e.Link)
+			}
+			e.Link = link
+		}
+		if err := extractEntry(dest, e); err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		entries = append(entries, name)
+		return nil
+	}
+
+	if format == "zip" {
+		err = readZip(archive, handle)
+	} else {
+		err = readTar(archive, format == "tar.gz", handle)
+	}
+	return entries, err
+}
+
+// stripComponents 去掉路径前 n 级，剩余为空时返回 false
+func stripComponents(name string, n int) (string, bool) {
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	if len(parts) <= n {
+		return "", false
+	}
+	rest := strings.Join(parts[n:], "/")
+	if rest == "" || rest == "." {
+		return "", false
+	}
+	return rest, true
+}
+
+// safeJoin 将归档内路径拼到 dest 下，拒绝绝对路径和 .. 越界（zip-slip）
+func safeJoin(dest, name string) (string, error) {
+	if path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("拒绝绝对路径")
+	}
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if !isWithin(dest, target) {
+		return "", fmt.Errorf("拒绝越出目标目录的路径")
+	}
+	return target, nil
+}
+
+// checkNoSymlinkParent 确认 target 在 dest 之下的各级父目录都不是符号链接，
+// 防止先解压一个链接再通过它写到别处
+func checkNoSymlinkParent(dest, target string) error {
+	rel, err := filepath.Rel(dest, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	cur := dest
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		cur = filepath.Join(cur, part)
+		info, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("拒绝通过符号链接 %s 写入", cur)
+		}
+	}
+	return nil
+}
+
+// checkSymlinkTarget 检查符号链接的目标仍在 dest 之下。
+//
+// 只看路径文本不够：目标中间的 ".." 会跟随已经（或之后才）解压出来的符号链接，
+// 如 d1/link -> .. 之后再解压 d2 -> d1/link/..。因此只允许 ".." 出现在目标开头：
+// 开头的 ".." 从链接所在的真实目录（父目录中没有符号链接，见 checkNoSymlinkParent）向上，
+// 与按文本计算的结果一致；之后只能逐级向下，经过的符号链接也都经过同样的检查，不会越出 dest
+func checkSymlinkTarget(dest, target, link string) error {
+	if filepath.IsAbs(link) || filepath.VolumeName(link) != "" {
+		return fmt.Errorf("拒绝指向绝对路径的符号链接 -> %s", link)
+	}
+	descending := false
+	for _, part := range strings.Split(filepath.ToSlash(link), "/") {
+		switch part {
+		case "..":
+			if descending {
+				return fmt.Errorf("拒绝中间含有 .. 的符号链接 -> %s", link)
+			}
+		case "", ".":
+		default:
+			descending = true
+		}
+	}
+	if !isWithin(dest, filepath.Join(filepath.Dir(target), filepath.FromSlash(link))) {
+		return fmt.Errorf("拒绝指向目标目录之外的符号链接 -> %s", link)
+	}
+	return nil
+}
+
+// extractEntry 解压单个条目
+func extractEntry(dest string, e archiveEntry) error {
+	target, err := safeJoin(dest, e.Name)
+	if err != nil {
+		return err
+	}
+	if err := checkNoSymlinkParent(dest, target); err != nil {
+		return err
+	}
+
+	switch {
+	case e.Mode.IsDir():
+		return os.MkdirAll(target, e.Mode.Perm()|0o700)
+
+	case e.Mode&fs.ModeSymlink != 0:
+		if err := checkSymlinkTarget(dest, target, e.Link); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		return os.Symlink(e.Link, target)
+
+	case e.Hard:
+		old, err := safeJoin(dest, e.Link)
+		if err != nil {
+			return fmt.Errorf("硬链接目标: %w", err)
+		}
+		// 源文件也不能经由已解压的符号链接指向目录之外
+		if err := checkNoSymlinkParent(dest, old); err != nil {
+			return fmt.Errorf("硬链接目标: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		return os.Link(old, target)
+
+	case e.Mode.IsRegular():
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		r, err := e.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		// 部分工具生成的 zip 不带权限位
+		perm := e.Mode.Perm()
+		if perm == 0 {
+			perm = 0o644
+		}
+		// 先删除已有条目，避免通过同名符号链接写到目录之外
+		os.Remove(target)
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(target, e.ModTime, e.ModTime)
+
+	default:
+		return fmt.Errorf("不支持的条目类型 %s", fileType(e.Mode))
+	}
+}
+
+// readTar 顺序读取 tar（可选 gzip 压缩）中的条目
+func readTar(archive string, gz bool, handle func(archiveEntry) error) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gz {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// 跳过 pax 全局头等元数据条目
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		e := archiveEntry{
+			Name:    hdr.Name,
+			Mode:    hdr.FileInfo().Mode(),
+			ModTime: hdr.ModTime,
+			Link:    hdr.Linkname,
+			Hard:    hdr.Typeflag == tar.TypeLink,
+			Open:    func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}
+		if err := handle(e); err != nil {
+			return err
+		}
+	}
+}
+
+// readZip 读取 zip 中的条目
+func readZip(archive string, handle func(archiveEntry) error) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		e := archiveEntry{
+			Name:    zf.Name,
+			Mode:    zf.Mode(),
+			ModTime: zf.Modified,
+			Open:    func() (io.ReadCloser, error) { return zf.Open() },
+		}
+		if strings.HasSuffix(zf.Name, "/") {
+			e.Mode |= fs.ModeDir
+		}
+		if e.Mode&fs.ModeSymlink != 0 {
+			r, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			link, err := io.ReadAll(io.LimitReader(r, 4096))
+			r.Close()
+			if err != nil {
+				return err
+			}
+			e.Link = string(link)
+		}
+		if err := handle(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
+	"github.com/spf13/cobra"
+)
+
+// globalOptions 根命令上的持久参数，所有子命令共享
+type globalOptions struct {
+	All    bool   // 显示隐藏文件
+	Follow bool   // 跟随符号链接
+	Output string // 输出格式：text | json
+}
+
+// getGlobalOptions 读取持久参数（子命令中也可以通过 cmd.Flags() 获取）
+func getGlobalOptions(cmd *cobra.Command) globalOptions {
+	all, _ := cmd.Flags().GetBool("all")
+	follow, _ := cmd.Flags().GetBool("follow")
+	output, _ := cmd.Flags().GetString("output")
+	return globalOptions{All: all, Follow: follow, Output: output}
+}
+
+// validOutput 校验输出格式
+func validOutput(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("输出格式必须是 text 或 json，当前为 %q", format)
+	}
+	return nil
+}
+
+// entryInfo 一个文件或目录的信息（同时用于 JSON 输出）
+type entryInfo struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"mod_time"`
+	Link    string    `json:"link,omitempty"` // 符号链接指向的目标
+}
+
+// newEntryInfo 从 fs.FileInfo 构造 entryInfo
+func newEntryInfo(path string, info fs.FileInfo) entryInfo {
+	e := entryInfo{
+		Name:    info.Name(),
+		Path:    path,
+		Type:    fileType(info.Mode()),
+		Size:    info.Size(),
+		Mode:    info.Mode().String(),
+		ModTime: info.ModTime(),
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		e.Link, _ = os.Readlink(path)
+	}
+	return e
+}
+
+// fileType 返回文件类型的简短名称
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode.IsRegular():
+		return "file"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	default:
+		return "other"
+	}
+}
+
+// isHidden 以 . 开头的文件视为隐藏文件（"." 和 ".." 除外）
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// statPath 根据是否跟随符号链接选择 Stat 或 Lstat
+func statPath(path string, follow bool) (fs.FileInfo, error) {
+	if follow {
+		return os.Stat(path)
+	}
+	return os.Lstat(path)
+}
+
+// readDir 读取目录并按名称排序，按需过滤隐藏文件
+func readDir(dir string, g globalOptions) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if !g.All && isHidden(e.Name()) {
+			continue
+		}
+		info, err := statPath(filepath.Join(dir, e.Name()), g.Follow)
+		if err != nil {
+			// 跟随失败（如悬空链接）时退回链接本身的信息
+			info, err = e.Info()
+			if err != nil {
+				return nil, err
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+// walkFunc 遍历回调：depth 为相对起点的层级（起点为 0），返回 filepath.SkipDir 跳过该目录
+type walkFunc func(path string, info fs.FileInfo, depth int) error
+
+// walker 目录遍历器，支持隐藏文件过滤、符号链接跟随（带循环检测）和错误计数
+type walker struct {
+	opts   globalOptions
+	errOut io.Writer
+	errors int
+
+	// filter 可选的 include/exclude 过滤：被排除的目录不会进入，不匹配的文件不会回调
+	filter *pathmatch.Filter
+}
+
+func newWalker(g globalOptions, errOut io.Writer) *walker {
+	return &walker{opts: g, errOut: errOut}
+}
+
+// report 输出一个错误并计数，遍历继续进行
+func (w *walker) report(path string, err error) {
+	w.errors++
+	fmt.Fprintf(w.errOut, "file-tool: %s: %v\n", path, err)
+}
+
+// exitError 需要特定退出状态的错误（如 diff 的 1 和 2）。err 为 nil 时只设置退出状态，不输出错误信息
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("退出状态 %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// Err 遍历中出现过错误时返回汇总错误，用于让命令以非零状态退出
+func (w *walker) Err() error {
+	if w.errors == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d 个条目处理失败", w.errors)
+}
+
+// Walk 深度优先遍历 root，目录内按名称排序
+func (w *walker) Walk(root string, fn walkFunc) error {
+	info, err := statPath(root, w.opts.Follow)
+	if err != nil {
+		w.report(root, err)
+		return nil
+	}
+	if w.filter != nil {
+		fn = w.filtered(root, fn)
+	}
+	return w.walk(root, info, 0, nil, fn)
+}
+
+// filtered 在回调外包一层路径过滤，路径相对于 root 匹配
+func (w *walker) filtered(root string, fn walkFunc) walkFunc {
+	return func(path string, info fs.FileInfo, depth int) error {
+		if depth == 0 {
+			return fn(path, info, depth)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fn(path, info, depth)
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if w.filter.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+		} else if !w.filter.MatchFile(rel) {
+			return nil
+		}
+		return fn(path, info, depth)
+	}
+}
+
+func (w *walker) walk(path string, info fs.FileInfo, depth int, ancestors []fs.FileInfo, fn walkFunc) error {
+	err := fn(path, info, depth)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	if err != nil || !info.IsDir() {
+		return err
+	}
+
+	// 跟随符号链接时，目录可能指回自己的祖先，需要检测循环
+	for _, a := range ancestors {
+		if os.SameFile(a, info) {
+			w.report(path, fmt.Errorf("检测到符号链接循环，已跳过"))
+			return nil
+		}
+	}
+
+	children, err := readDir(path, w.opts)
+	if err != nil {
+		w.report(path, err)
+		return nil
+	}
+
+	ancestors = append(ancestors, info)
+	for _, child := range children {
+		if err := w.walk(filepath.Join(path, child.Name()), child, depth+1, ancestors, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// humanSize 将字节数格式化为易读的形式，如 1.5K、20M
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// pathArgs 未指定路径时默认使用当前目录
+func pathArgs(args []string) []string {
+	if len(args) == 0 {
+		return []string{"."}
+	}
+	return args
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// fileDiff 两个文件的比较结果
+type fileDiff struct {
+	Path   string     `json:"path,omitempty"` // 目录比较时为相对路径
+	A      string     `json:"a"`
+	B      string     `json:"b"`
+	Binary bool       `json:"binary,omitempty"` // 二进制文件只报告是否不同
+	Hunks  []diffHunk `json:"hunks"`
+}
+
+// dirDiff 两个目录的比较结果，路径均相对于比较的根目录
+type dirDiff struct {
+	A       string     `json:"a"`
+	B       string     `json:"b"`
+	Added   []string   `json:"added"`   // 只在 B 中
+	Removed []string   `json:"removed"` // 只在 A 中
+	Changed []fileDiff `json:"changed"`
+}
+
+// newDiffCmd 比较两个文件（unified diff）或两个目录（递归）
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff A B",
+		Short: "比较两个文件或两个目录（unified diff）",
+		Long: `比较两个文件时输出 unified diff；比较两个目录时列出新增、删除和变化的文件，
+并对变化的文本文件逐个输出 diff。二进制文件只报告是否不同。
+
+与 diff(1) 一致，退出状态 0 表示相同，1 表示有差异，2 表示出错（如文件无法读取）。`,
+		Example: `  file-tool diff old.conf new.conf -U 5
+  file-tool diff release-1.0 release-1.1
+  file-tool diff a b --json`,
+		// 与 diff(1) 一致：参数错误也以状态 2 退出
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return &exitError{2, err}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			differ, err := runDiff(cmd, args[0], args[1])
+			switch {
+			case err != nil:
+				return &exitError{2, err}
+			case differ:
+				return &exitError{code: 1}
+			}
+			return nil
+		},
+	}
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &exitError{2, err}
+	})
+	cmd.Flags().IntP("context", "U", 3, "每处改动前后显示的相同行数")
+	cmd.Flags().Bool("json", false, "以 JSON 输出（等同于 -o json）")
+	return cmd
+}
+
+// runDiff 执行比较并输出结果，返回两边是否不同
+func runDiff(cmd *cobra.Command, a, b string) (bool, error) {
+	g := getGlobalOptions(cmd)
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		g.Output = "json"
+	}
+	context, _ := cmd.Flags().GetInt("context")
+	if context < 0 {
+		return false, fmt.Errorf("--context 不能为负数")
+	}
+
+	ai, err := statPath(a, true)
+	if err != nil {
+		return false, err
+	}
+	bi, err := statPath(b, true)
+	if err != nil {
+		return false, err
+	}
+	out := cmd.OutOrStdout()
+
+	switch {
+	case ai.IsDir() && bi.IsDir():
+		w := newWalker(g, cmd.ErrOrStderr())
+		d, err := diffDirs(w, a, b, context)
+		if err != nil {
+			return false, err
+		}
+		if g.Output == "json" {
+			if err := writeJSON(out, d); err != nil {
+				return false, err
+			}
+		} else {
+			printDirDiff(out, d)
+		}
+		return len(d.Added)+len(d.Removed)+len(d.Changed) > 0, w.Err()
+
+	case ai.IsDir() || bi.IsDir():
+		return false, fmt.Errorf("不能比较目录和文件：%s、%s", a, b)
+	}
+
+	fd, err := diffFiles(a, b, context)
+	if err != nil {
+		return false, err
+	}
+	if g.Output == "json" {
+		if err := writeJSON(out, fd); err != nil {
+			return false, err
+		}
+	} else {
+		printFileDiff(out, fd)
+	}
+	return fd.Binary || len(fd.Hunks) > 0, nil
+}
+
+// readDiffFile 读取文件内容并判断是否为二进制
+func readDiffFile(path string) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	binary, err := isBinary(f)
+	if err != nil || binary {
+		return "", binary, err
+	}
+	data, err := io.ReadAll(f)
+	return string(data), false, err
+}
+
+// diffFiles 比较两个文件，内容相同时 Hunks 为空
+func diffFiles(a, b string, context int) (fileDiff, error) {
+	d := fileDiff{A: a, B: b, Hunks: []diffHunk{}}
+	ta, binA, err := readDiffFile(a)
+	if err != nil {
+		return d, err
+	}
+	tb, binB, err := readDiffFile(b)
+	if err != nil {
+		return d, err
+	}
+
+	if binA || binB {
+		same, err := sameContent(a, b)
+		d.Binary = !same
+		return d, err
+	}
+	if ta == tb {
+		return d, nil
+	}
+	la, lb := splitLines(ta), splitLines(tb)
+	d.Hunks = makeHunks(diffLines(la, lb), la, lb, context)
+	return d, nil
+}
+
+// sameContent 按大小和 SHA-256 判断两个文件内容是否相同
+func sameContent(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	reason, err := fileDiffers(a, b, ai, bi, true)
+	return reason == "", err
+}
+
+// diffDirs 递归比较两个目录：只在一侧存在的条目计入新增或删除，两侧都是文件且内容不同的计入变化，
+// 类型不同（如一侧是文件、一侧是目录）时按删除加新增处理
+func diffDirs(w *walker, a, b string, context int) (dirDiff, error) {
+	d := dirDiff{A: a, B: b, Added: []string{}, Removed: []string{}, Changed: []fileDiff{}}
+	aInfos, aOrder := collectTree(w, a)
+	bInfos, bOrder := collectTree(w, b)
+
+	for _, rel := range aOrder {
+		bi, ok := bInfos[rel]
+		ai := aInfos[rel]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, rel)
+		case ai.IsDir() != bi.IsDir():
+			d.Removed = append(d.Removed, rel)
+			d.Added = append(d.Added, rel)
+		case ai.Mode().IsRegular() && bi.Mode().IsRegular():
+			pa, pb := filepath.Join(a, rel), filepath.Join(b, rel)
+			same, err := sameContent(pa, pb)
+			if err != nil {
+				w.report(rel, err)
+				continue
+			}
+			if same {
+				continue
+			}
+			fd, err := diffFiles(pa, pb, context)
+			if err != nil {
+				w.report(rel, err)
+				continue
+			}
+			fd.Path = filepath.ToSlash(rel)
+			d.Changed = append(d.Changed, fd)
+		}
+	}
+	for _, rel := range bOrder {
+		if _, ok := aInfos[rel]; !ok {
+			d.Added = append(d.Added, rel)
+		}
+	}
+
+	// 只列出最上层的新增、删除目录，其中的内容不再逐个列出
+	d.Added = topLevelOnly(d.Added, bInfos)
+	d.Removed = topLevelOnly(d.Removed, aInfos)
+	return d, nil
+}
+
+// topLevelOnly 去掉位于已列出目录之下的路径，并转换为 / 分隔
+func topLevelOnly(paths []string, infos map[string]fs.FileInfo) []string {
+	sort.Strings(paths)
+	result := []string{}
+	var dirs []string
+	for _, p := range paths {
+		covered := false
+		for _, d := range dirs {
+			if isWithin(d, p) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		if info, ok := infos[p]; ok && info.IsDir() {
+			dirs = append(dirs, p)
+		}
+		result = append(result, filepath.ToSlash(p))
+	}
+	return result
+}
+
+// printFileDiff 以 unified diff 格式输出，头部带修改时间
+func printFileDiff(w io.Writer, d fileDiff) {
+	if d.Binary {
+		fmt.Fprintf(w, "二进制文件 %s 和 %s 不同\n", d.A, d.B)
+		return
+	}
+	if len(d.Hunks) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "--- %s\t%s\n", d.A, diffTime(d.A))
+	fmt.Fprintf(w, "+++ %s\t%s\n", d.B, diffTime(d.B))
+	writeHunks(w, d.Hunks)
+}
+
+func diffTime(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return info.ModTime().Format("2006-01-02 15:04:05.000000000 -0700")
+}
+
+func printDirDiff(w io.Writer, d dirDiff) {
+	for _, p := range d.Removed {
+		fmt.Fprintf(w, "仅在 %s 中: %s\n", d.A, p)
+	}
+	for _, p := range d.Added {
+		fmt.Fprintf(w, "仅在 %s 中: %s\n", d.B, p)
+	}
+	for _, fd := range d.Changed {
+		printFileDiff(w, fd)
+	}
+	if len(d.Removed)+len(d.Added)+len(d.Changed) > 0 {
+		fmt.Fprintf(w, "----\n删除 %d，新增 %d，变化 %d\n", len(d.Removed), len(d.Added), len(d.Changed))
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+)
+
+// duEntry 一个目录（或文件）的占用统计
+type duEntry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+}
+
+// newDuCmd 统计目录占用空间（按文件实际大小累计）
+func newDuCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "du [PATH...]",
+		Short: "统计目录占用空间",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			maxDepth, _ := cmd.Flags().GetInt("depth")
+			human, _ := cmd.Flags().GetBool("human")
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			var result []duEntry
+
+			for _, p := range pathArgs(args) {
+				result = append(result, diskUsage(w, p, maxDepth)...)
+			}
+
+			if g.Output == "json" {
+				if err := writeJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+				return w.Err()
+			}
+
+			for _, e := range result {
+				size := fmt.Sprintf("%d", e.Size)
+				if human {
+					size = humanSize(e.Size)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", size, e.Path)
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().IntP("depth", "d", 0, "显示到第几层子目录（0 只显示总计）")
+	cmd.Flags().BoolP("human", "H", false, "以易读单位显示大小")
+	return cmd
+}
+
+// diskUsage 统计 root 的占用，返回层级不超过 maxDepth 的目录统计（子目录在前，与 du 一致）
+func diskUsage(w *walker, root string, maxDepth int) []duEntry {
+	type frame struct {
+		duEntry
+		depth int
+	}
+	var stack []frame
+	var result []duEntry
+
+	// pop 弹出栈顶目录，把统计累加到父目录
+	pop := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if len(stack) > 0 {
+			stack[len(stack)-1].Size += top.Size
+			stack[len(stack)-1].Files += top.Files
+		}
+		if top.depth <= maxDepth {
+			result = append(result, top.duEntry)
+		}
+	}
+
+	w.Walk(root, func(path string, info fs.FileInfo, depth int) error {
+		// 深度优先遍历：遇到同层或更浅的条目时，说明之前的目录已遍历完
+		for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+			pop()
+		}
+		if info.IsDir() {
+			stack = append(stack, frame{duEntry: duEntry{Path: path}, depth: depth})
+			return nil
+		}
+		if len(stack) == 0 {
+			// 起点本身是文件
+			result = append(result, duEntry{Path: path, Size: info.Size(), Files: 1})
+			return nil
+		}
+		stack[len(stack)-1].Size += info.Size()
+		stack[len(stack)-1].Files++
+		return nil
+	})
+
+	for len(stack) > 0 {
+		pop()
+	}
+	return result
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// 检测结果的编码名称
+const (
+	encASCII   = "ascii" // 纯 ASCII，同时也是合法的 UTF-8 和 GBK
+	encUTF8    = "utf-8"
+	encUTF8BOM = "utf-8-bom"
+	encUTF16LE = "utf-16le"
+	encUTF16BE = "utf-16be"
+	encGBK     = "gbk"
+	encGB18030 = "gb18030"
+	encBinary  = "binary"
+	encUnknown = "unknown"
+)
+
+// fromEncodings --from 可以指定的源编码
+var fromEncodings = []string{"auto", encUTF8, encUTF8BOM, encUTF16LE, encUTF16BE, encGBK, encGB18030}
+
+var bomUTF8 = []byte{0xEF, 0xBB, 0xBF}
+
+// detectEncoding 按 BOM、UTF-16 零字节分布、UTF-8 合法性、GBK/GB18030 字节结构的顺序判断编码
+func detectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return encUTF8BOM
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return encUTF16LE
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return encUTF16BE
+	}
+	if enc := guessUTF16(data); enc != "" {
+		return enc
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return encBinary // 文本编码中只有 UTF-16 会出现零字节
+	}
+	if isASCII(data) {
+		return encASCII
+	}
+	if utf8.Valid(data) {
+		return encUTF8
+	}
+	return guessGB(data)
+}
+
+// guessUTF16 识别没有 BOM 的 UTF-16：ASCII 字符在 UTF-16 中有一个字节为 0，
+// 零字节只出现在奇数位是小端，只出现在偶数位是大端；再确认解出的字符中没有控制字符，以排除二进制文件
+func guessUTF16(data []byte) string {
+	sample := data[:min(len(data), 4096)&^1]
+	if len(sample) < 4 {
+		return ""
+	}
+	var even, odd int
+	for i := 0; i < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case odd*10 >= pairs && even*100 <= pairs && plainUTF16(sample, true):
+		return encUTF16LE
+	case even*10 >= pairs && odd*100 <= pairs && plainUTF16(sample, false):
+		return encUTF16BE
+	}
+	return ""
+}
+
+// plainUTF16 按 UTF-16 解读时不包含除制表、换行、回车以外的控制字符
+func plainUTF16(sample []byte, littleEndian bool) bool {
+	for i := 0; i+1 < len(sample); i += 2 {
+		u := uint16(sample[i])<<8 | uint16(sample[i+1])
+		if littleEndian {
+			u = uint16(sample[i+1])<<8 | uint16(sample[i])
+		}
+		if u < 0x20 && u != '\t' && u != '\n' && u != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// guessGB 按字节结构判断 GBK 或 GB18030：GBK 为双字节（首字节 0x81-0xFE，尾字节 0x40-0xFE 且不为 0x7F），
+// GB18030 额外有四字节序列（第 2、4 字节为 0x30-0x39）。GBK 是 GB18030 的子集，出现四字节序列时才判为 GB18030。
+func guessGB(data []byte) string {
+	four := false
+	for i := 0; i < len(data); {
+		b := data[i]
+		if b < 0x80 {
+			i++
+			continue
+		}
+		if b == 0x80 || b == 0xFF || i+1 >= len(data) {
+			return encUnknown
+		}
+		b2 := data[i+1]
+		if b2 >= 0x30 && b2 <= 0x39 {
+			if i+3 >= len(data) || data[i+2] < 0x81 || data[i+2] == 0xFF || data[i+3] < 0x30 || data[i+3] > 0x39 {
+				return encUnknown
+			}
+			four = true
+			i += 4
+			continue
+		}
+		if b2 < 0x40 || b2 == 0x7F || b2 == 0xFF {
+			return encUnknown
+		}
+		i += 2
+	}
+	if four {
+		return encGB18030
+	}
+	return encGBK
+}
+
+// toUTF8 把 data 从 enc 编码转换为不带 BOM 的 UTF-8
+func toUTF8(data []byte, enc string) ([]byte, error) {
+	var t transform.Transformer
+	switch enc {
+	case encASCII, encUTF8:
+		return data, nil
+	case encUTF8BOM:
+		return bytes.TrimPrefix(data, bomUTF8), nil
+	case encUTF16LE:
+		t = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder() // 有 BOM 时去掉 BOM
+	case encUTF16BE:
+		t = unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+	case encGBK:
+		t = simplifiedchinese.GBK.NewDecoder()
+	case encGB18030:
+		t = simplifiedchinese.GB18030.NewDecoder()
+	default:
+		return nil, fmt.Errorf("不支持的编码 %q", enc)
+	}
+	out, _, err := transform.Bytes(t, data)
+	return out, err
+}
+
+// encodingFile 一个待处理的文件，Rel 为相对命令行参数的路径（用于 --out-dir）
+type encodingFile struct {
+	Path string
+	Rel  string
+	Info fs.FileInfo
+}
+
+// collectEncodingFiles 收集参数中的普通文件，目录只展开第一层，-r 时递归
+func collectEncodingFiles(w *walker, paths []string, recursive bool) []encodingFile {
+	var files []encodingFile
+	for _, root := range paths {
+		w.Walk(root, func(path string, info fs.FileInfo, depth int) error {
+			if info.IsDir() {
+				if depth > 0 && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel := filepath.Base(path)
+			if depth > 0 {
+				rel, _ = filepath.Rel(root, path)
+			}
+			files = append(files, encodingFile{Path: path, Rel: rel, Info: info})
+			return nil
+		})
+	}
+	return files
+}
+
+// newEncodingCmd 编码检测与转换
+func newEncodingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encoding",
+		Short: "检测文本编码，把 GBK、GB18030、UTF-16 文件转换为 UTF-8",
+	}
+	cmd.PersistentFlags().BoolP("recursive", "r", false, "递归处理子目录中的文件")
+
+	cmd.AddCommand(newEncodingDetectCmd())
+	cmd.AddCommand(newEncodingConvertCmd())
+	return cmd
+}
+
+func newEncodingDetectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "detect [PATH...]",
+		Short: "检测文件编码（utf-8、utf-8-bom、gbk、gb18030、utf-16le/be）",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			recursive, _ := cmd.Flags().GetBool("recursive")
+
+			type result struct {
+				Path     string `json:"path"`
+				Encoding string `json:"encoding"`
+			}
+			w := newWalker(g, cmd.ErrOrStderr())
+			results := []result{}
+			for _, f := range collectEncodingFiles(w, pathArgs(args), recursive) {
+				data, err := os.ReadFile(f.Path)
+				if err != nil {
+					w.report(f.Path, err)
+					continue
+				}
+				results = append(results, result{f.Path, detectEncoding(data)})
+			}
+
+			if g.Output == "json" {
+				if err := writeJSON(cmd.OutOrStdout(), results); err != nil {
+					return err
+				}
+				return w.Err()
+			}
+			for _, r := range results {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-10s %s\n", r.Encoding, r.Path)
+			}
+			return w.Err()
+		},
+	}
+}
+
+// convertItem 一个文件的转换结果
+type convertItem struct {
+	Path   string `json:"path"`
+	Dest   string `json:"dest,omitempty"`
+	From   string `json:"from"`
+	Action string `json:"action"` // convert | skip | error
+	Reason string `json:"reason,omitempty"`
+}
+
+// convertOptions encoding convert 参数
+type convertOptions struct {
+	From   string // auto 表示自动检测
+	BOM    bool   // 输出带 BOM 的 UTF-8（Excel 打开 CSV 需要）
+	OutDir string // 为空时原地转换
+	DryRun bool
+}
+
+func newEncodingConvertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert [PATH...]",
+		Short: "把文件转换为 UTF-8（原地或输出到目录，跳过二进制文件）",
+		Example: `  file-tool encoding convert data.csv --dry-run
+  file-tool encoding convert ./reports -r --out-dir ./utf8
+  file-tool encoding convert export.csv --from gb18030 --bom`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			for _, e := range fromEncodings {
+				if from == e {
+					return nil
+				}
+			}
+			return fmt.Errorf("--from 必须是 %v 之一，当前为 %q", fromEncodings, from)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			var o convertOptions
+			o.From, _ = cmd.Flags().GetString("from")
+			o.BOM, _ = cmd.Flags().GetBool("bom")
+			o.OutDir, _ = cmd.Flags().GetString("out-dir")
+			o.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			files := collectEncodingFiles(w, pathArgs(args), recursive)
+			if o.OutDir != "" {
+				if conflicts := checkOutDirConflicts(files, o.OutDir); len(conflicts) > 0 {
+					for _, c := range conflicts {
+						fmt.Fprintf(cmd.ErrOrStderr(), "file-tool: %s\n", c)
+					}
+					return fmt.Errorf("发现 %d 处输出路径冲突，未做任何修改", len(conflicts))
+				}
+			}
+			items := []convertItem{}
+			failed := 0
+			for _, f := range files {
+				it := convertFile(f, o)
+				if it.Action == "error" {
+					failed++
+				}
+				items = append(items, it)
+			}
+
+			if err := printConvertResult(cmd.OutOrStdout(), g, items, o.DryRun); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d 个文件转换失败", failed)
+			}
+			return w.Err()
+		},
+	}
+	cmd.Flags().String("from", "auto", "源编码（auto|utf-8|utf-8-bom|utf-16le|utf-16be|gbk|gb18030）")
+	cmd.Flags().Bool("bom", false, "输出带 BOM 的 UTF-8（便于 Excel 正确识别 CSV）")
+	cmd.Flags().StringP("out-dir", "d", "", "输出目录，保留相对路径（默认原地转换）")
+	cmd.Flags().BoolP("dry-run", "n", false, "只显示将要转换的文件，不做修改")
+	return cmd
+}
+
+// checkOutDirConflicts --out-dir 时检查不同的源文件是否会写到同一个目标（如 a/x.txt 和 b/x.txt），
+// 否则后写的会覆盖先写的
+func checkOutDirConflicts(files []encodingFile, outDir string) []string {
+	seen := make(map[string]encodingFile)
+	var conflicts []string
+	for _, f := range files {
+		dest := filepath.Join(outDir, f.Rel)
+		prev, ok := seen[dest]
+		if !ok {
+			seen[dest] = f
+			continue
+		}
+		if !sameFile(prev.Path, f.Path) {
+			conflicts = append(conflicts, fmt.Sprintf("%s 和 %s 都会输出到 %s", prev.Path, f.Path, dest))
+		}
+	}
+	return conflicts
+}
+
+// sameFile 两个路径是否指向同一个文件（同一个文件在参数中出现多次时不算冲突）
+func sameFile(a, b string) bool {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	return err == nil && os.SameFile(ia, ib)
+}
+
+// convertFile 转换单个文件；已是目标格式的文件、二进制文件和无法识别编码的文件保持不变
+func convertFile(f encodingFile, o convertOptions) convertItem {
+	it := convertItem{Path: f.Path, Dest: f.Path, From: o.From}
+	fail := func(err error) convertItem {
+		it.Action, it.Reason = "error", err.Error()
+		return it
+	}
+	if o.OutDir != "" {
+		it.Dest = filepath.Join(o.OutDir, f.Rel)
+	} else if li, err := os.Lstat(f.Path); err == nil && li.Mode()&fs.ModeSymlink != 0 {
+		// -L 时参数或目录中的符号链接也会被转换，要写到链接指向的文件，不能把链接替换成普通文件
+		real, err := filepath.EvalSymlinks(f.Path)
+		if err != nil {
+			return fail(err)
+		}
+		it.Dest = real
+	}
+	skip := func(reason string) convertItem {
+		it.Action, it.Reason, it.Dest = "skip", reason, ""
+		return it
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return fail(err)
+	}
+	// 即使用 --from 指定了编码，二进制文件也不做转换
+	detected := detectEncoding(data)
+	if detected == encBinary {
+		return skip("二进制文件")
+	}
+	if o.From == "auto" {
+		it.From = detected
+	}
+	switch it.From {
+	case encUnknown:
+		return skip("无法识别编码，可用 --from 指定")
+	case encASCII, encUTF8:
+		if !o.BOM {
+			return skip("已是 UTF-8")
+		}
+	case encUTF8BOM:
+		if o.BOM {
+			return skip("已是带 BOM 的 UTF-8")
+		}
+	}
+
+	out, err := toUTF8(data, it.From)
+	if err != nil {
+		return fail(fmt.Errorf("按 %s 解码失败: %w", it.From, err))
+	}
+	if o.BOM {
+		out = append(append([]byte{}, bomUTF8...), out...)
+	}
+
+	it.Action = "convert"
+	if o.DryRun {
+		return it
+	}
+	if err := writeFileAtomic(it.Dest, out, f.Info.Mode().Perm()); err != nil {
+		return fail(err)
+	}
+	return it
+}
+
+// writeFileAtomic 先写临时文件再改名，写入中途失败不会破坏原文件
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".file-tool-encoding-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func printConvertResult(w io.Writer, g globalOptions, items []convertItem, dryRun bool) error {
+	if g.Output == "json" {
+		return writeJSON(w, struct {
+			DryRun bool          `json:"dry_run"`
+			Items  []convertItem `json:"items"`
+		}{dryRun, items})
+	}
+
+	converted := 0
+	for _, it := range items {
+		switch it.Action {
+		case "convert":
+			converted++
+			target := ""
+			if it.Dest != it.Path {
+				target = " -> " + it.Dest
+			}
+			fmt.Fprintf(w, "转换  %s（%s -> utf-8）%s\n", it.Path, it.From, target)
+		case "skip":
+			fmt.Fprintf(w, "跳过  %s：%s\n", it.Path, it.Reason)
+		case "error":
+			fmt.Fprintf(w, "失败  %s：%s\n", it.Path, it.Reason)
+		}
+	}
+	if dryRun {
+		fmt.Fprintf(w, "试运行：%d 个文件需要转换，未做任何修改\n", converted)
+	}
+	return nil
+}
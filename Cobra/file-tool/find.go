@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// findOptions find 命令的过滤条件
+type findOptions struct {
+	Name     string // 文件名通配符，如 *.go
+	Type     string // f（文件）| d（目录）| l（符号链接），空表示不限
+	MinSize  int64  // 最小大小（字节），-1 表示不限
+	MaxSize  int64  // 最大大小（字节），-1 表示不限
+	MaxDepth int    // 最大层级，0 表示不限
+}
+
+// match 判断条目是否满足过滤条件
+func (o findOptions) match(info fs.FileInfo) bool {
+	if o.Name != "" {
+		if ok, _ := filepath.Match(o.Name, info.Name()); !ok {
+			return false
+		}
+	}
+	switch o.Type {
+	case "f":
+		if !info.Mode().IsRegular() {
+			return false
+		}
+	case "d":
+		if !info.IsDir() {
+			return false
+		}
+	case "l":
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return false
+		}
+	}
+	if o.MinSize >= 0 && info.Size() < o.MinSize {
+		return false
+	}
+	if o.MaxSize >= 0 && info.Size() > o.MaxSize {
+		return false
+	}
+	return true
+}
+
+// newFindCmd 按条件查找文件
+func newFindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find [PATH...]",
+		Short: "按条件查找文件",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			if _, err := filepath.Match(name, ""); err != nil {
+				return fmt.Errorf("无效的通配符 %q: %w", name, err)
+			}
+			typ, _ := cmd.Flags().GetString("type")
+			if typ != "" && typ != "f" && typ != "d" && typ != "l" {
+				return fmt.Errorf("--type 必须是 f、d 或 l，当前为 %q", typ)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			var o findOptions
+			o.Name, _ = cmd.Flags().GetString("name")
+			o.Type, _ = cmd.Flags().GetString("type")
+			o.MinSize, _ = cmd.Flags().GetInt64("min-size")
+			o.MaxSize, _ = cmd.Flags().GetInt64("max-size")
+			o.MaxDepth, _ = cmd.Flags().GetInt("max-depth")
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			var result []entryInfo
+
+			for _, p := range pathArgs(args) {
+				w.Walk(p, func(path string, info fs.FileInfo, depth int) error {
+					if o.match(info) {
+						if g.Output == "json" {
+							result = append(result, newEntryInfo(path, info))
+						} else {
+							fmt.Fprintln(cmd.OutOrStdout(), path)
+						}
+					}
+					if info.IsDir() && o.MaxDepth > 0 && depth >= o.MaxDepth {
+						return filepath.SkipDir
+					}
+					return nil
+				})
+			}
+
+			if g.Output == "json" {
+				if result == nil {
+					result = []entryInfo{}
+				}
+				if err := writeJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("name", "n", "", "文件名通配符（如 *.go）")
+	cmd.Flags().StringP("type", "t", "", "类型：f 文件 | d 目录 | l 符号链接")
+	cmd.Flags().Int64("min-size", -1, "最小大小（字节）")
+	cmd.Flags().Int64("max-size", -1, "最大大小（字节）")
+	cmd.Flags().Int("max-depth", 0, "最大查找层级（0 表示不限制）")
+	return cmd
+}
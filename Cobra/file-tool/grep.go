@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/pathmatch"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/spf13/cobra"
+)
+
+// grepLine 一行输出：匹配行或上下文行
+type grepLine struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Context bool   `json:"context,omitempty"` // true 表示上下文行
+}
+
+// grepResult 单个文件的搜索结果
+type grepResult struct {
+	Path   string     `json:"path"`
+	Count  int        `json:"count"`
+	Lines  []grepLine `json:"lines,omitempty"`
+	Binary bool       `json:"-"`
+	Err    error      `json:"-"`
+}
+
+// grepOptions grep 命令参数
+type grepOptions struct {
+	Re      *regexp.Regexp
+	Context int  // 匹配行前后输出的行数
+	Count   bool // 只输出匹配行数
+}
+
+// newGrepCmd 并发搜索文件内容
+func newGrepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grep PATTERN [PATH...]",
+		Short: "并发搜索文件内容（自动跳过二进制文件）",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+			word, _ := cmd.Flags().GetBool("word")
+			fixed, _ := cmd.Flags().GetBool("fixed-strings")
+			include, _ := cmd.Flags().GetStringArray("include")
+			exclude, _ := cmd.Flags().GetStringArray("exclude")
+			workers, _ := cmd.Flags().GetInt("workers")
+			var o grepOptions
+			o.Context, _ = cmd.Flags().GetInt("context")
+			o.Count, _ = cmd.Flags().GetBool("count")
+
+			re, err := compileGrepPattern(args[0], ignoreCase, word, fixed)
+			if err != nil {
+				return err
+			}
+			o.Re = re
+
+			filter, err := pathmatch.NewFilter(include, exclude, "")
+			if err != nil {
+				return err
+			}
+			w := newWalker(g, cmd.ErrOrStderr())
+			w.filter = filter
+
+			return runGrep(cmd, g, w, o, pathArgs(args[1:]), workers)
+		},
+	}
+
+	cmd.Flags().BoolP("ignore-case", "i", false, "忽略大小写")
+	cmd.Flags().BoolP("word", "w", false, "只匹配完整单词")
+	cmd.Flags().BoolP("fixed-strings", "F", false, "把 PATTERN 当作普通字符串而不是正则")
+	cmd.Flags().IntP("context", "C", 0, "输出匹配行前后的 N 行")
+	cmd.Flags().BoolP("count", "c", false, "只输出每个文件的匹配行数")
+	cmd.Flags().StringArray("include", nil, "只搜索匹配的文件（通配符，支持 **，可多次指定）")
+	cmd.Flags().StringArray("exclude", nil, "排除匹配的文件或目录（通配符，支持 **，可多次指定）")
+	cmd.Flags().IntP("workers", "j", runtime.NumCPU(), "并发搜索的文件数")
+	return cmd
+}
+
+// compileGrepPattern 根据 -i、-w、-F 构造正则
+func compileGrepPattern(pattern string, ignoreCase, word, fixed bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if word {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if ignoreCase {
+		pattern = `(?i)` + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("无效的正则: %w", err)
+	}
+	return re, nil
+}
+
+// runGrep 遍历与搜索并行进行：遍历产生任务，worker 池搜索，结果按遍历顺序输出
+func runGrep(cmd *cobra.Command, g globalOptions, w *walker, o grepOptions, paths []string, workers int) error {
+	type job struct {
+		index int
+		path  string
+	}
+	type indexed struct {
+		index int
+		grepResult
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan job)
+	results := make(chan indexed)
+
+	// 遍历：只在这个 goroutine 中使用 walker
+	go func() {
+		defer close(jobs)
+		i := 0
+		for _, p := range paths {
+			w.Walk(p, func(path string, info fs.FileInfo, depth int) error {
+				if info.Mode().IsRegular() {
+					jobs <- job{i, path}
+					i++
+				}
+				return nil
+			})
+		}
+	}()
+
+	// worker 池
+	done := make(chan struct{})
+	for n := 0; n < workers; n++ {
+		go func() {
+			for j := range jobs {
+				results <- indexed{j.index, grepFile(j.path, o)}
+			}
+			done <- struct{}{}
+		}()
+	}
+	go func() {
+		for n := 0; n < workers; n++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	// 结果可能乱序到达，暂存后按序号依次输出，保证输出顺序稳定
+	out := cmd.OutOrStdout()
+	pending := make(map[int]grepResult)
+	next, failed := 0, 0
+	var all []grepResult
+
+	for r := range results {
+		pending[r.index] = r.grepResult
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			switch {
+			case res.Err != nil:
+				failed++
+				fmt.Fprintf(cmd.ErrOrStderr(), "file-tool: %s: %v\n", res.Path, res.Err)
+			case res.Binary || res.Count == 0:
+			case g.Output == "json":
+				all = append(all, res)
+			default:
+				printGrepResult(out, res, o)
+			}
+		}
+	}
+
+	if g.Output == "json" {
+		if all == nil {
+			all = []grepResult{}
+		}
+		if err := writeJSON(out, all); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件搜索失败", failed)
+	}
+	return w.Err()
+}
+
+// isBinary 按文件头判断是否为二进制（文本类型都以 text/plain 为祖先）
+func isBinary(f *os.File) (bool, error) {
+	mt, err := mimetype.DetectReader(f)
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	for m := mt; m != nil; m = m.Parent() {
+		if m.Is("text/plain") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// grepFile 搜索单个文件，按需收集上下文行
+func grepFile(path string, o grepOptions) grepResult {
+	res := grepResult{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer f.Close()
+
+	if res.Binary, res.Err = isBinary(f); res.Binary || res.Err != nil {
+		return res
+	}
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var before []grepLine // 最近的 Context 行，用于前置上下文
+	after := 0            // 还需要输出的后置上下文行数
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		text := sc.Text()
+
+		if o.Re.MatchString(text) {
+			res.Count++
+			if !o.Count {
+				res.Lines = append(res.Lines, before...)
+				res.Lines = append(res.Lines, grepLine{Line: lineNo, Text: text})
+				before = before[:0]
+				after = o.Context
+			}
+			continue
+		}
+		if o.Count || o.Context == 0 {
+			continue
+		}
+		if after > 0 {
+			res.Lines = append(res.Lines, grepLine{Line: lineNo, Text: text, Context: true})
+			after--
+			continue
+		}
+		before = append(before, grepLine{Line: lineNo, Text: text, Context: true})
+		if len(before) > o.Context {
+			before = before[1:]
+		}
+	}
+	res.Err = sc.Err()
+	return res
+}
+
+// printGrepResult 以 grep 风格输出：匹配行用 path:行号:内容，上下文行用 path-行号-内容，
+// 不连续的片段之间用 -- 分隔
+func printGrepResult(w io.Writer, r grepResult, o grepOptions) {
+	if o.Count {
+		fmt.Fprintf(w, "%s:%d\n", r.Path, r.Count)
+		return
+	}
+	prev := 0
+	for _, l := range r.Lines {
+		if o.Context > 0 && prev > 0 && l.Line > prev+1 {
+			fmt.Fprintln(w, "--")
+		}
+		sep := ":"
+		if l.Context {
+			sep = "-"
+		}
+		fmt.Fprintf(w, "%s%s%d%s%s\n", r.Path, sep, l.Line, sep, l.Text)
+		prev = l.Line
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// newLsCmd 列出目录内容
+func newLsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ls [PATH...]",
+		Short: "列出目录内容",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			long, _ := cmd.Flags().GetBool("long")
+			human, _ := cmd.Flags().GetBool("human")
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			result := make(map[string][]entryInfo) // 仅用于 JSON 输出
+			paths := pathArgs(args)
+
+			for i, p := range paths {
+				var entries []entryInfo
+				w.Walk(p, func(path string, info fs.FileInfo, depth int) error {
+					// 起点是目录时列出其子项，是文件时列出自身
+					if depth == 0 && info.IsDir() {
+						return nil
+					}
+					entries = append(entries, newEntryInfo(path, info))
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				})
+
+				if g.Output == "json" {
+					result[p] = entries
+					continue
+				}
+
+				out := cmd.OutOrStdout()
+				if len(paths) > 1 {
+					if i > 0 {
+						fmt.Fprintln(out)
+					}
+					fmt.Fprintf(out, "%s:\n", p)
+				}
+				for _, e := range entries {
+					if !long {
+						fmt.Fprintln(out, e.Name)
+						continue
+					}
+					size := fmt.Sprintf("%d", e.Size)
+					if human {
+						size = humanSize(e.Size)
+					}
+					name := e.Name
+					if e.Link != "" {
+						name += " -> " + e.Link
+					}
+					fmt.Fprintf(out, "%s %10s %s %s\n", e.Mode, size, e.ModTime.Format("2006-01-02 15:04"), name)
+				}
+			}
+
+			if g.Output == "json" {
+				if err := writeJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().BoolP("long", "l", false, "长格式（权限、大小、修改时间）")
+	cmd.Flags().BoolP("human", "H", false, "以易读单位显示大小")
+	return cmd
+}
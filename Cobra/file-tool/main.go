@@ -1,16 +1,55 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 )
 
 func main() {
 	// 1. 创建根命令（顶级命令）
-	rootCmd := &cobra.Command{}
+	rootCmd := &cobra.Command{
+		Use:           "file-tool",
+		Short:         "文件工具集",
+		Long:          "file-tool 是一个多命令文件工具：列目录、目录树、空间统计、文件信息、查找、同步、归档、批量重命名、内容搜索、编码转换、文件比较等",
+		SilenceUsage:  true, // 执行期错误不输出用法
+		SilenceErrors: true, // 错误统一由 main 输出
+	}
+
+	// 2. 全局参数：所有子命令共享
+	rootCmd.PersistentFlags().BoolP("all", "a", false, "显示隐藏文件")
+	rootCmd.PersistentFlags().BoolP("follow", "L", false, "跟随符号链接")
+	rootCmd.PersistentFlags().StringP("output", "o", "text", "输出格式（text|json）")
+
+	// 参数验证：对所有子命令生效
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		return validOutput(output)
+	}
+
+	// 3. 构建命令树
+	rootCmd.AddCommand(newLsCmd())
+	rootCmd.AddCommand(newTreeCmd())
+	rootCmd.AddCommand(newDuCmd())
+	rootCmd.AddCommand(newStatCmd())
+	rootCmd.AddCommand(newFindCmd())
+	rootCmd.AddCommand(newSyncCmd())
+	rootCmd.AddCommand(newArchiveCmd())
+	rootCmd.AddCommand(newRenameCmd())
+	rootCmd.AddCommand(newGrepCmd())
+	rootCmd.AddCommand(newEncodingCmd())
+	rootCmd.AddCommand(newDiffCmd())
 
 	if err := rootCmd.Execute(); err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) {
+			if ee.err != nil {
+				log.Print(ee.err)
+			}
+			os.Exit(ee.code)
+		}
 		log.Fatal(err)
 	}
 }
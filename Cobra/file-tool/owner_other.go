@@ -0,0 +1,17 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// owner 非 Unix 平台没有 UID/GID，保持为空
+type owner struct {
+	UID   uint32 `json:"uid,omitempty"`
+	GID   uint32 `json:"gid,omitempty"`
+	User  string `json:"user,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+func fileOwner(info fs.FileInfo) owner {
+	return owner{}
+}
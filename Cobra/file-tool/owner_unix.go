@@ -0,0 +1,40 @@
+//go:build unix
+
+package main
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// owner 文件属主信息
+type owner struct {
+	UID   uint32 `json:"uid"`
+	GID   uint32 `json:"gid"`
+	User  string `json:"user"`
+	Group string `json:"group"`
+}
+
+// fileOwner 读取文件属主，用户名/组名查不到时使用数字 ID
+func fileOwner(info fs.FileInfo) owner {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return owner{}
+	}
+
+	o := owner{
+		UID:   st.Uid,
+		GID:   st.Gid,
+		User:  strconv.FormatUint(uint64(st.Uid), 10),
+		Group: strconv.FormatUint(uint64(st.Gid), 10),
+	}
+	if u, err := user.LookupId(o.User); err == nil {
+		o.User = u.Username
+	}
+	if g, err := user.LookupGroupId(o.Group); err == nil {
+		o.Group = g.Name
+	}
+	return o
+}
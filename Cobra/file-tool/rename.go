@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Satori2Core/LearnGoPkgTools/Cobra/internal/table"
+	"github.com/spf13/cobra"
+)
+
+// renameItem 一次重命名：同一目录下 From -> To
+type renameItem struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// renameJournal 重命名日志，用于 --undo 恢复原名
+type renameJournal struct {
+	Version int          `json:"version"`
+	Created time.Time    `json:"created"`
+	Undone  bool         `json:"undone"`
+	Items   []renameItem `json:"items"`
+}
+
+// placeholderRe 模板占位符：{index}、{index:3}、{date}、{date:2006-01-02}、{name}、{ext}
+var placeholderRe = regexp.MustCompile(`\{(index|date|name|ext)(?::([^}]*))?\}`)
+
+// newRenameCmd 批量重命名
+func newRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename [PATH...]",
+		Short: "按正则或模板批量重命名（可撤销）",
+		Long: `按正则或模板批量重命名文件。执行前先输出预览表并检查冲突，
+每次执行都会写入日志文件，可通过 rename --undo <日志> 恢复原名。
+
+模板占位符：
+  {index}     序号，{index:3} 表示补零到 3 位
+  {date}      修改日期，默认 20060102，可写 {date:2006-01-02}
+  {name}      原文件名（不含扩展名）
+  {ext}       扩展名（含点，如 .jpg）`,
+		Example: `  file-tool rename ./photos --template "trip_{index:3}{ext}" --dry-run
+  file-tool rename ./logs --regex '^app-(\d+)\.log$' --replace 'app_$1.log'
+  file-tool rename --undo .file-tool-rename-20240131-080000.json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			undo, _ := cmd.Flags().GetString("undo")
+			pattern, _ := cmd.Flags().GetString("regex")
+			template, _ := cmd.Flags().GetString("template")
+			switch {
+			case undo != "":
+				if pattern != "" || template != "" {
+					return fmt.Errorf("--undo 不能与 --regex 或 --template 同时使用")
+				}
+			case pattern == "" && template == "":
+				return fmt.Errorf("需要指定 --regex 或 --template")
+			case pattern != "" && template != "":
+				return fmt.Errorf("--regex 和 --template 只能指定一个")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			undo, _ := cmd.Flags().GetString("undo")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			if undo != "" {
+				return runRenameUndo(cmd, g, undo, dryRun)
+			}
+
+			pattern, _ := cmd.Flags().GetString("regex")
+			replace, _ := cmd.Flags().GetString("replace")
+			template, _ := cmd.Flags().GetString("template")
+			start, _ := cmd.Flags().GetInt("start")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			journalPath, _ := cmd.Flags().GetString("journal")
+
+			w := newWalker(g, cmd.ErrOrStderr())
+			files := collectRenameFiles(w, pathArgs(args), recursive)
+
+			var namer func(i int, path string, info fs.FileInfo) (string, bool)
+			if pattern != "" {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("无效的正则 %q: %w", pattern, err)
+				}
+				namer = regexNamer(re, replace)
+			} else {
+				namer = templateNamer(template, start)
+			}
+
+			items, err := planRename(files, namer)
+			if err != nil {
+				return err
+			}
+			conflicts := checkRenameConflicts(items)
+
+			if err := printRenamePlan(cmd.OutOrStdout(), g, items, conflicts, dryRun); err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				return fmt.Errorf("发现 %d 处冲突，未做任何修改", len(conflicts))
+			}
+			if dryRun || len(items) == 0 {
+				return w.Err()
+			}
+
+			// 先写日志再改名：即使中途失败，也能根据日志恢复
+			now := time.Now()
+			if journalPath == "" {
+				// 带上纳秒和进程号，同一秒内多次执行也不会重名
+				journalPath = fmt.Sprintf(".file-tool-rename-%s-%09d-%d.json", now.Format("20060102-150405"), now.Nanosecond(), os.Getpid())
+			}
+			journal := renameJournal{Version: 1, Created: now, Items: absRenameItems(items)}
+			if err := createRenameJournal(journalPath, journal); err != nil {
+				return fmt.Errorf("写入日志失败: %w", err)
+			}
+
+			if err := applyRename(items); err != nil {
+				return fmt.Errorf("%w（可使用 --undo %s 恢复）", err, journalPath)
+			}
+			if g.Output != "json" {
+				fmt.Fprintf(cmd.OutOrStdout(), "已重命名 %d 个文件，日志：%s\n", len(items), journalPath)
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().String("regex", "", "匹配原文件名的正则表达式")
+	cmd.Flags().String("replace", "", "正则替换内容，可使用 $1、${name} 引用分组")
+	cmd.Flags().StringP("template", "t", "", "新文件名模板，如 photo_{index:3}{ext}")
+	cmd.Flags().Int("start", 1, "模板中 {index} 的起始值")
+	cmd.Flags().BoolP("recursive", "r", false, "递归处理子目录中的文件")
+	cmd.Flags().BoolP("dry-run", "n", false, "只显示预览，不做修改")
+	cmd.Flags().String("journal", "", "日志文件路径（默认写到当前目录），不能是已存在的文件")
+	cmd.Flags().String("undo", "", "根据日志文件恢复原名")
+	return cmd
+}
+
+// collectRenameFiles 收集要重命名的普通文件，目录参数展开为其中的文件（按名称排序）
+func collectRenameFiles(w *walker, paths []string, recursive bool) []string {
+	var files []string
+	for _, p := range paths {
+		w.Walk(p, func(path string, info fs.FileInfo, depth int) error {
+			if info.IsDir() {
+				if depth > 0 && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// regexNamer 正则模式：只重命名匹配的文件
+func regexNamer(re *regexp.Regexp, replace string) func(int, string, fs.FileInfo) (string, bool) {
+	return func(_ int, path string, _ fs.FileInfo) (string, bool) {
+		name := filepath.Base(path)
+		if !re.MatchString(name) {
+			return "", false
+		}
+		return re.ReplaceAllString(name, replace), true
+	}
+}
+
+// templateNamer 模板模式：所有文件按顺序编号
+func templateNamer(template string, start int) func(int, string, fs.FileInfo) (string, bool) {
+	return func(i int, path string, info fs.FileInfo) (string, bool) {
+		base := filepath.Base(path)
+		ext := filepath.Ext(base)
+		return placeholderRe.ReplaceAllStringFunc(template, func(m string) string {
+			sub := placeholderRe.FindStringSubmatch(m)
+			key, arg := sub[1], sub[2]
+			switch key {
+			case "index":
+				width, _ := strconv.Atoi(arg)
+				return fmt.Sprintf("%0*d", width, start+i)
+			case "date":
+				if arg == "" {
+					arg = "20060102"
+				}
+				return info.ModTime().Format(arg)
+			case "name":
+				return strings.TrimSuffix(base, ext)
+			default: // ext
+				return ext
+			}
+		}), true
+	}
+}
+
+// planRename 生成重命名计划，跳过名称不变的文件
+func planRename(files []string, namer func(int, string, fs.FileInfo) (string, bool)) ([]renameItem, error) {
+	var items []renameItem
+	index := 0
+	for _, path := range files {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		newName, ok := namer(index, path, info)
+		if !ok {
+			continue
+		}
+		index++
+		if newName == "" || newName == "." || newName == ".." || strings.ContainsRune(newName, filepath.Separator) {
+			return nil, fmt.Errorf("%s: 生成的文件名 %q 无效", path, newName)
+		}
+		to := filepath.Join(filepath.Dir(path), newName)
+		if to == path {
+			continue
+		}
+		items = append(items, renameItem{From: path, To: to})
+	}
+	return items, nil
+}
+
+// checkRenameConflicts 检查冲突：多个文件改成同一个名字，或目标已存在且不在本次改名范围内
+func checkRenameConflicts(items []renameItem) map[string]string {
+	conflicts := make(map[string]string) // 目标路径 -> 原因
+	sources := make(map[string]bool, len(items))
+	for _, it := range items {
+		sources[it.From] = true
+	}
+
+	targets := make(map[string]string)
+	for _, it := range items {
+		if prev, ok := targets[it.To]; ok {
+			conflicts[it.To] = fmt.Sprintf("与 %s 的新名称相同", filepath.Base(prev))
+			continue
+		}
+		targets[it.To] = it.From
+		// 目标已存在但本身也会被改名（如互换名称）时不算冲突
+		if _, err := os.Lstat(it.To); err == nil && !sources[it.To] {
+			conflicts[it.To] = "目标文件已存在"
+		}
+	}
+	return conflicts
+}
+
+// printRenamePlan 输出预览表
+func printRenamePlan(w io.Writer, g globalOptions, items []renameItem, conflicts map[string]string, dryRun bool) error {
+	if g.Output == "json" {
+		type row struct {
+			renameItem
+			Conflict string `json:"conflict,omitempty"`
+		}
+		rows := make([]row, 0, len(items))
+		for _, it := range items {
+			rows = append(rows, row{it, conflicts[it.To]})
+		}
+		return writeJSON(w, struct {
+			DryRun bool  `json:"dry_run"`
+			Items  []row `json:"items"`
+		}{dryRun, rows})
+	}
+
+	if len(items) == 0 {
+		_, err := fmt.Fprintln(w, "没有需要重命名的文件")
+		return err
+	}
+
+	// 按最长原名的显示宽度对齐，中文等宽字符占两列
+	width := 0
+	for _, it := range items {
+		width = max(width, table.Width(it.From))
+	}
+	for i, it := range items {
+		pad := strings.Repeat(" ", width-table.Width(it.From))
+		line := fmt.Sprintf("%4d  %s%s  ->  %s", i+1, it.From, pad, filepath.Base(it.To))
+		if reason, ok := conflicts[it.To]; ok {
+			line += "  [冲突：" + reason + "]"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "试运行：共 %d 个文件，未做任何修改\n", len(items))
+	}
+	return nil
+}
+
+// applyRename 分两步改名：先全部改为临时名，再改为目标名，
+// 这样 a->b、b->a 这样的互换也不会互相覆盖
+func applyRename(items []renameItem) error {
+	tmp := make([]string, len(items))
+	stamp := time.Now().UnixNano()
+	for i, it := range items {
+		tmp[i] = filepath.Join(filepath.Dir(it.From), fmt.Sprintf(".file-tool-rename-%d-%d", stamp, i))
+		if err := os.Rename(it.From, tmp[i]); err != nil {
+			// 回滚已经改为临时名的文件
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(tmp[j], items[j].From)
+			}
+			return fmt.Errorf("%s: %w", it.From, err)
+		}
+	}
+
+	var failed []string
+	for i, it := range items {
+		if err := os.Rename(tmp[i], it.To); err != nil {
+			// 无法改为目标名时恢复原名
+			os.Rename(tmp[i], it.From)
+			failed = append(failed, fmt.Sprintf("%s: %v", it.From, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d 个文件重命名失败：%s", len(failed), strings.Join(failed, "；"))
+	}
+	return nil
+}
+
+// absRenameItems 日志中使用绝对路径，以便在任意目录下撤销
+func absRenameItems(items []renameItem) []renameItem {
+	result := make([]renameItem, len(items))
+	for i, it := range items {
+		from, err := filepath.Abs(it.From)
+		if err != nil {
+			from = it.From
+		}
+		to, err := filepath.Abs(it.To)
+		if err != nil {
+			to = it.To
+		}
+		result[i] = renameItem{From: from, To: to}
+	}
+	return result
+}
+
+// createRenameJournal 创建新的日志文件，已存在时报错，不覆盖之前的日志
+func createRenameJournal(path string, j renameJournal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeRenameJournal 更新日志文件（先写临时文件再重命名）
+func writeRenameJournal(path string, j renameJournal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// runRenameUndo 根据日志恢复原名
+func runRenameUndo(cmd *cobra.Command, g globalOptions, journalPath string, dryRun bool) error {
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		return err
+	}
+	var journal renameJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return fmt.Errorf("%s 不是有效的重命名日志: %w", journalPath, err)
+	}
+	if journal.Undone {
+		return fmt.Errorf("%s 已经撤销过", journalPath)
+	}
+
+	// 反向操作：To -> From；当前不存在的文件（如已被手动处理）跳过
+	var items []renameItem
+	for _, it := range journal.Items {
+		if _, err := os.Lstat(it.To); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "file-tool: 跳过 %s: %v\n", it.To, err)
+			continue
+		}
+		items = append(items, renameItem{From: it.To, To: it.From})
+	}
+
+	conflicts := checkRenameConflicts(items)
+	if err := printRenamePlan(cmd.OutOrStdout(), g, items, conflicts, dryRun); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("发现 %d 处冲突，未做任何修改", len(conflicts))
+	}
+	if dryRun || len(items) == 0 {
+		return nil
+	}
+
+	if err := applyRename(items); err != nil {
+		return err
+	}
+	journal.Undone = true
+	if err := writeRenameJournal(journalPath, journal); err != nil {
+		return fmt.Errorf("已恢复，但更新日志失败: %w", err)
+	}
+	if g.Output != "json" {
+		fmt.Fprintf(cmd.OutOrStdout(), "已恢复 %d 个文件的原名\n", len(items))
+	}
+	return nil
+}
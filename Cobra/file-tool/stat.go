@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// statInfo stat 命令输出的详细信息
+type statInfo struct {
+	entryInfo
+	Perm  string `json:"perm"` // 八进制权限，如 0644
+	owner        // 属主信息（平台相关）
+}
+
+// newStatCmd 显示文件详细信息
+func newStatCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stat PATH...",
+		Short: "显示文件详细信息",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			w := newWalker(g, cmd.ErrOrStderr())
+			var result []statInfo
+
+			for _, p := range args {
+				info, err := statPath(p, g.Follow)
+				if err != nil {
+					w.report(p, err)
+					continue
+				}
+				result = append(result, statInfo{
+					entryInfo: newEntryInfo(p, info),
+					Perm:      fmt.Sprintf("%04o", info.Mode().Perm()),
+					owner:     fileOwner(info),
+				})
+			}
+
+			if g.Output == "json" {
+				if err := writeJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+				return w.Err()
+			}
+
+			out := cmd.OutOrStdout()
+			for i, s := range result {
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				fmt.Fprintf(out, "  文件: %s\n", s.Path)
+				if s.Link != "" {
+					fmt.Fprintf(out, "  链接: %s\n", s.Link)
+				}
+				fmt.Fprintf(out, "  类型: %s\n", s.Type)
+				fmt.Fprintf(out, "  大小: %d（%s）\n", s.Size, humanSize(s.Size))
+				fmt.Fprintf(out, "  权限: %s（%s）\n", s.Perm, s.Mode)
+				if s.User != "" {
+					fmt.Fprintf(out, "  属主: %s(%d) / %s(%d)\n", s.User, s.UID, s.Group, s.GID)
+				}
+				fmt.Fprintf(out, "  修改: %s\n", s.ModTime.Format("2006-01-02 15:04:05 -0700"))
+			}
+			return w.Err()
+		},
+	}
+}
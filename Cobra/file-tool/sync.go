@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// syncAction 同步计划中的一步操作
+type syncAction struct {
+	Op     string `json:"op"`     // mkdir | copy | update | symlink | delete
+	Path   string `json:"path"`   // 相对路径
+	Reason string `json:"reason"` // 执行原因
+	Error  string `json:"error,omitempty"`
+}
+
+// syncOptions sync 命令参数
+type syncOptions struct {
+	Checksum bool // 按内容校验和比较（默认按大小和修改时间）
+	Delete   bool // 删除目标中多余的文件
+	DryRun   bool // 只输出计划，不做修改
+}
+
+// syncSummary 同步结果汇总
+type syncSummary struct {
+	Planned   int `json:"planned"`
+	Unchanged int `json:"unchanged"`
+	Done      int `json:"done"`
+	Failed    int `json:"failed"`
+}
+
+// newSyncCmd 单向镜像目录：让 DST 与 SRC 保持一致
+func newSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync SRC DST",
+		Short: "单向同步目录（镜像 SRC 到 DST）",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			var o syncOptions
+			o.Checksum, _ = cmd.Flags().GetBool("checksum")
+			o.Delete, _ = cmd.Flags().GetBool("delete")
+			o.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+			src, dst := filepath.Clean(args[0]), filepath.Clean(args[1])
+			if err := checkSyncPaths(src, dst); err != nil {
+				return err
+			}
+
+			// 同步要得到完整的镜像，不论是否指定 -a 都包含隐藏文件，否则它们既不会被复制，也不会被 --delete 删除
+			g.All = true
+			w := newWalker(g, cmd.ErrOrStderr())
+			plan, unchanged := planSync(w, src, dst, o)
+			summary := syncSummary{Planned: len(plan), Unchanged: unchanged}
+
+			if !o.DryRun {
+				for i := range plan {
+					if err := applySyncAction(src, dst, plan[i], g.Follow); err != nil {
+						plan[i].Error = err.Error()
+						summary.Failed++
+						fmt.Fprintf(cmd.ErrOrStderr(), "file-tool: %s %s: %v\n", plan[i].Op, plan[i].Path, err)
+						continue
+					}
+					summary.Done++
+				}
+			}
+
+			if g.Output == "json" {
+				if plan == nil {
+					plan = []syncAction{}
+				}
+				err := writeJSON(cmd.OutOrStdout(), struct {
+					DryRun  bool         `json:"dry_run"`
+					Actions []syncAction `json:"actions"`
+					Summary syncSummary  `json:"summary"`
+				}{o.DryRun, plan, summary})
+				if err != nil {
+					return err
+				}
+			} else {
+				out := cmd.OutOrStdout()
+				for _, a := range plan {
+					status := ""
+					if a.Error != "" {
+						status = "  [失败]"
+					}
+					fmt.Fprintf(out, "%-7s %s（%s）%s\n", a.Op, a.Path, a.Reason, status)
+				}
+				if o.DryRun {
+					fmt.Fprintf(out, "试运行：计划 %d 项操作，%d 项无变化，未做任何修改\n", summary.Planned, summary.Unchanged)
+				} else {
+					fmt.Fprintf(out, "完成 %d 项，失败 %d 项，%d 项无变化\n", summary.Done, summary.Failed, summary.Unchanged)
+				}
+			}
+
+			if summary.Failed > 0 {
+				return fmt.Errorf("%d 项操作失败", summary.Failed)
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().BoolP("checksum", "c", false, "按内容校验和比较文件（默认比较大小和修改时间）")
+	cmd.Flags().Bool("delete", false, "删除目标目录中源目录没有的文件")
+	cmd.Flags().BoolP("dry-run", "n", false, "只显示同步计划，不做任何修改")
+	return cmd
+}
+
+// checkSyncPaths 源必须是目录，且源和目标不能互相包含
+func checkSyncPaths(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s 不是目录", src)
+	}
+
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	absDst, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	if isWithin(absSrc, absDst) || isWithin(absDst, absSrc) {
+		return fmt.Errorf("源目录和目标目录不能相同或互相包含")
+	}
+	return nil
+}
+
+// isWithin 判断 path 是否等于 dir 或位于 dir 之下
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// collectTree 遍历目录，返回 相对路径 -> 信息 以及按遍历顺序排列的相对路径
+func collectTree(w *walker, root string) (map[string]fs.FileInfo, []string) {
+	infos := make(map[string]fs.FileInfo)
+	var order []string
+	w.Walk(root, func(path string, info fs.FileInfo, depth int) error {
+		if depth == 0 {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		infos[rel] = info
+		order = append(order, rel)
+		return nil
+	})
+	return infos, order
+}
+
+// planSync 比较两棵目录树，生成同步计划，并返回无变化的条目数
+func planSync(w *walker, src, dst string, o syncOptions) ([]syncAction, int) {
+	srcInfos, srcOrder := collectTree(w, src)
+
+	dstInfos := map[string]fs.FileInfo{}
+	var dstOrder []string
+	if _, err := os.Lstat(dst); err == nil {
+		dstInfos, dstOrder = collectTree(w, dst)
+	}
+
+	var plan []syncAction
+	unchanged := 0
+
+	// 目标中需要先删除的条目（类型不一致时先删后建）
+	replaced := make(map[string]bool)
+
+	for _, rel := range srcOrder {
+		s := srcInfos[rel]
+		d, exists := dstInfos[rel]
+
+		if exists && fileType(s.Mode()) != fileType(d.Mode()) {
+			plan = append(plan, syncAction{Op: "delete", Path: rel, Reason: "类型不同，需要替换"})
+			replaced[rel] = true
+			exists = false
+		}
+
+		switch {
+		case s.IsDir():
+			if !exists {
+				plan = append(plan, syncAction{Op: "mkdir", Path: rel, Reason: "目标不存在"})
+			} else {
+				unchanged++
+			}
+
+		case s.Mode()&fs.ModeSymlink != 0:
+			target, _ := os.Readlink(filepath.Join(src, rel))
+			if exists {
+				if cur, _ := os.Readlink(filepath.Join(dst, rel)); cur == target {
+					unchanged++
+					continue
+				}
+			}
+			plan = append(plan, syncAction{Op: "symlink", Path: rel, Reason: "链接目标 " + target})
+
+		case s.Mode().IsRegular():
+			if !exists {
+				plan = append(plan, syncAction{Op: "copy", Path: rel, Reason: "目标不存在"})
+				continue
+			}
+			reason, err := fileDiffers(filepath.Join(src, rel), filepath.Join(dst, rel), s, d, o.Checksum)
+			if err != nil {
+				w.report(rel, err)
+				continue
+			}
+			if reason == "" {
+				unchanged++
+				continue
+			}
+			plan = append(plan, syncAction{Op: "update", Path: rel, Reason: reason})
+
+		default:
+			w.report(filepath.Join(src, rel), fmt.Errorf("不支持同步的文件类型 %s", fileType(s.Mode())))
+		}
+	}
+
+	if o.Delete {
+		// 只删除最上层的多余条目，其子项随之删除
+		var extra []string
+		for _, rel := range dstOrder {
+			if _, ok := srcInfos[rel]; ok || underReplaced(rel, replaced) {
+				continue
+			}
+			extra = append(extra, rel)
+		}
+		sort.Strings(extra)
+		var last string
+		for _, rel := range extra {
+			if last != "" && isWithin(last, rel) {
+				continue
+			}
+			plan = append(plan, syncAction{Op: "delete", Path: rel, Reason: "源目录中不存在"})
+			last = rel
+		}
+	}
+
+	return plan, unchanged
+}
+
+// underReplaced 判断 rel 本身或其上级目录是否已因类型不同被替换
+func underReplaced(rel string, replaced map[string]bool) bool {
+	for p := rel; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if replaced[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDiffers 比较两个文件，返回不同的原因；相同则返回空字符串
+func fileDiffers(srcPath, dstPath string, s, d fs.FileInfo, checksum bool) (string, error) {
+	if s.Size() != d.Size() {
+		return "大小不同", nil
+	}
+	if checksum {
+		a, err := fileChecksum(srcPath)
+		if err != nil {
+			return "", err
+		}
+		b, err := fileChecksum(dstPath)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(a, b) {
+			return "校验和不同", nil
+		}
+		return "", nil
+	}
+	// 不同文件系统的时间精度不同，按秒比较
+	if !s.ModTime().Truncate(time.Second).Equal(d.ModTime().Truncate(time.Second)) {
+		return "修改时间不同", nil
+	}
+	return "", nil
+}
+
+// fileChecksum 计算文件内容的 SHA-256
+func fileChecksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// applySyncAction 执行一步同步操作
+func applySyncAction(src, dst string, a syncAction, follow bool) error {
+	srcPath := filepath.Join(src, a.Path)
+	dstPath := filepath.Join(dst, a.Path)
+
+	switch a.Op {
+	case "mkdir":
+		info, err := statPath(srcPath, follow)
+		if err != nil {
+			return err
+		}
+		return os.MkdirAll(dstPath, info.Mode().Perm())
+	case "copy", "update":
+		return atomicCopy(srcPath, dstPath)
+	case "symlink":
+		target, err := os.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+		// 先在临时名称上创建链接再重命名，保证替换是原子的
+		tmp := filepath.Join(filepath.Dir(dstPath), fmt.Sprintf(".file-tool-sync-%d", time.Now().UnixNano()))
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, dstPath); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return nil
+	case "delete":
+		return os.RemoveAll(dstPath)
+	}
+	return fmt.Errorf("未知操作 %q", a.Op)
+}
+
+// atomicCopy 先写入目标目录中的临时文件，再重命名为目标文件，
+// 这样中途失败不会留下写了一半的目标文件。权限和修改时间与源文件一致。
+func atomicCopy(srcPath, dstPath string) (err error) {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dstPath), ".file-tool-sync-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, in); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dstPath)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffEdit 编辑脚本中的一步：' ' 相同、'-' 只在旧文件中、'+' 只在新文件中
+type diffEdit struct {
+	Kind byte
+	A, B int // 在旧、新文件中的行下标（不适用时为 -1）
+}
+
+// splitLines 按行切分并保留换行符，这样“末尾有无换行”也会被当作差异
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 用 Myers 算法计算最短编辑脚本。先去掉相同的首尾行，减少算法处理的规模。
+func diffLines(a, b []string) []diffEdit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var edits []diffEdit
+	for i := 0; i < pre; i++ {
+		edits = append(edits, diffEdit{' ', i, i})
+	}
+	for _, e := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		if e.A >= 0 {
+			e.A += pre
+		}
+		if e.B >= 0 {
+			e.B += pre
+		}
+		edits = append(edits, e)
+	}
+	for i := suf; i > 0; i-- {
+		edits = append(edits, diffEdit{' ', len(a) - i, len(b) - i})
+	}
+	return edits
+}
+
+// maxDiffSteps 每次寻找 middle snake 时最多搜索的步数（约为编辑距离的一半）
+const maxDiffSteps = 4096
+
+// myers 线性空间的 Myers 算法：同时从两端搜索，找到最短编辑路径中间的一段相同行（middle snake），
+// 再对其前后两部分递归。时间 O((N+M)D)，空间 O(N+M)，不随改动行数平方增长
+func myers(a, b []string) []diffEdit {
+	d := &differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	return d.edits
+}
+
+type differ struct {
+	a, b  []string
+	edits []diffEdit
+}
+
+// compare 计算 a[aLo:aHi] 与 b[bLo:bHi] 的编辑脚本并追加到 d.edits
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, diffEdit{' ', aLo, bLo})
+		aLo++
+		bLo++
+	}
+	suf := 0
+	for aLo < aHi-suf && bLo < bHi-suf && d.a[aHi-1-suf] == d.b[bHi-1-suf] {
+		suf++
+	}
+	aHi, bHi = aHi-suf, bHi-suf
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.edits = append(d.edits, diffEdit{'+', -1, j})
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.edits = append(d.edits, diffEdit{'-', i, -1})
+		}
+	default:
+		// 去掉首尾相同行后两边都不为空，编辑距离至少为 2，前后两部分都严格变小，递归必然结束
+		x, y, u, v, ok := d.middleSnake(aLo, aHi, bLo, bHi)
+		if !ok {
+			// 差异太大，不再寻找最短路径，整段按删除后插入输出
+			for i := aLo; i < aHi; i++ {
+				d.edits = append(d.edits, diffEdit{'-', i, -1})
+			}
+			for j := bLo; j < bHi; j++ {
+				d.edits = append(d.edits, diffEdit{'+', -1, j})
+			}
+			break
+		}
+		d.compare(aLo, x, bLo, y)
+		for i := 0; i < u-x; i++ {
+			d.edits = append(d.edits, diffEdit{' ', x + i, y + i})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+
+	for i := suf; i > 0; i-- {
+		d.edits = append(d.edits, diffEdit{' ', aHi + suf - i, bHi + suf - i})
+	}
+}
+
+// middleSnake 返回最短编辑路径中间那段相同行的起点 (x, y) 和终点 (u, v)。
+// 正向记录各对角线 k = x-y 能到达的最远 x；反向在倒置的序列上做同样的搜索，两者相遇时即找到。
+// 搜索超过 maxDiffSteps 步时放弃并返回 false，避免整体改写的大文件耗时过长
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta&1 != 0
+	maxD := (n + m + 1) / 2
+	off := maxD + 1
+	vf := make([]int, 2*maxD+3) // 正向：对角线 k 上最远的 x
+	vb := make([]int, 2*maxD+3) // 反向：倒置后对角线 kr 上最远的 x（kr = delta - k）
+
+	for step := 0; step <= maxD; step++ {
+		if step > maxDiffSteps {
+			return 0, 0, 0, 0, false
+		}
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && vf[off+k-1] < vf[off+k+1]) {
+				px = vf[off+k+1]
+			} else {
+				px = vf[off+k-1] + 1
+			}
+			py := px - k
+			sx := px
+			for px < n && py < m && d.a[aLo+px] == d.b[bLo+py] {
+				px++
+				py++
+			}
+			vf[off+k] = px
+			// delta 为奇数时在正向检查是否与上一步的反向路径重叠
+			if kr := delta - k; odd && kr >= -(step-1) && kr <= step-1 && px+vb[off+kr] >= n {
+				return aLo + sx, bLo + sx - k, aLo + px, bLo + py, true
+			}
+		}
+		for kr := -step; kr <= step; kr += 2 {
+			var px int
+			if kr == -step || (kr != step && vb[off+kr-1] < vb[off+kr+1]) {
+				px = vb[off+kr+1]
+			} else {
+				px = vb[off+kr-1] + 1
+			}
+			py := px - kr
+			sx := px
+			for px < n && py < m && d.a[aHi-1-px] == d.b[bHi-1-py] {
+				px++
+				py++
+			}
+			vb[off+kr] = px
+			// delta 为偶数时在反向检查是否与同一步的正向路径重叠；换算回原坐标，snake 为 (n-px, m-py) 到 (n-sx, m-sy)
+			if k := delta - kr; !odd && k >= -step && k <= step && vf[off+k]+px >= n {
+				return aLo + n - px, bLo + m - py, aLo + n - sx, bLo + m - (sx - kr), true
+			}
+		}
+	}
+	panic("myers: middle snake not found") // 不会到达：step = ceil((n+m)/2) 时两端必然相遇
+}
+
+// diffHunk unified diff 中的一段
+type diffHunk struct {
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"` // 每行以 ' '、'-'、'+' 开头，保留原换行符
+}
+
+// makeHunks 把编辑脚本按上下文行数分段，间隔不超过 2*context 的改动合并为一段
+func makeHunks(edits []diffEdit, a, b []string, context int) []diffHunk {
+	var hunks []diffHunk
+	oldPos, newPos := 0, 0 // 当前编辑之前两侧各有多少行
+	advance := func(from, to int) {
+		for _, e := range edits[from:to] {
+			if e.Kind != '+' {
+				oldPos++
+			}
+			if e.Kind != '-' {
+				newPos++
+			}
+		}
+	}
+
+	done := 0 // 已计入 oldPos/newPos 的编辑数
+	for i := 0; i < len(edits); i++ {
+		if edits[i].Kind == ' ' {
+			continue
+		}
+
+		// 向后扩展，直到连续超过 2*context 行都相同；再去掉多余的相同行，只保留 context 行
+		end, last := i, i
+		for same := 0; end < len(edits) && same <= 2*context; end++ {
+			if edits[end].Kind == ' ' {
+				same++
+			} else {
+				same, last = 0, end
+			}
+		}
+		start := max(i-context, 0)
+		end = min(last+1+context, len(edits))
+
+		advance(done, start)
+		h := diffHunk{OldStart: oldPos + 1, NewStart: newPos + 1}
+		for _, e := range edits[start:end] {
+			switch e.Kind {
+			case ' ':
+				h.Lines = append(h.Lines, " "+a[e.A])
+				h.OldLines++
+				h.NewLines++
+			case '-':
+				h.Lines = append(h.Lines, "-"+a[e.A])
+				h.OldLines++
+			case '+':
+				h.Lines = append(h.Lines, "+"+b[e.B])
+				h.NewLines++
+			}
+		}
+		// 与 GNU diff 一致：某侧行数为 0 时，起始行号为改动位置之前的那一行
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		hunks = append(hunks, h)
+
+		advance(start, end)
+		done = end
+		i = end - 1
+	}
+	return hunks
+}
+
+// writeHunks 输出 unified diff 的各段，行数为 1 时省略行数（与 GNU diff 一致）
+func writeHunks(w io.Writer, hunks []diffHunk) {
+	rng := func(start, lines int) string {
+		if lines == 1 {
+			return fmt.Sprint(start)
+		}
+		return fmt.Sprintf("%d,%d", start, lines)
+	}
+	for _, h := range hunks {
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", rng(h.OldStart, h.OldLines), rng(h.NewStart, h.NewLines))
+		for _, l := range h.Lines {
+			if strings.HasSuffix(l, "\n") {
+				io.WriteString(w, l)
+			} else {
+				fmt.Fprintf(w, "%s\n\\ No newline at end of file\n", l)
+			}
+		}
+	}
+}
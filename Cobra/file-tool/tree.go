@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// treeNode 目录树节点（用于 JSON 输出）
+type treeNode struct {
+	entryInfo
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+// newTreeCmd 以树状结构显示目录
+func newTreeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tree [PATH]",
+		Short: "以树状结构显示目录",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g := getGlobalOptions(cmd)
+			maxDepth, _ := cmd.Flags().GetInt("depth")
+			dirsOnly, _ := cmd.Flags().GetBool("dirs-only")
+			root := pathArgs(args)[0]
+
+			// 先构建整棵树，再按格式输出
+			w := newWalker(g, cmd.ErrOrStderr())
+			nodes := make(map[string]*treeNode)
+			var top *treeNode
+			var dirs, files int
+
+			w.Walk(root, func(path string, info fs.FileInfo, depth int) error {
+				if depth > 0 && dirsOnly && !info.IsDir() {
+					return nil
+				}
+				node := &treeNode{entryInfo: newEntryInfo(path, info)}
+				if depth == 0 {
+					node.Name = path
+					top = node
+				} else {
+					parent := nodes[filepath.Dir(path)]
+					parent.Children = append(parent.Children, node)
+					if info.IsDir() {
+						dirs++
+					} else {
+						files++
+					}
+				}
+				if info.IsDir() {
+					// 子条目的路径由 filepath.Join 生成，已经是规范形式；根路径（如 ./t、t/）也要规范化后再作键
+					nodes[filepath.Clean(path)] = node
+					if maxDepth > 0 && depth >= maxDepth {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			})
+
+			if top == nil {
+				return w.Err()
+			}
+
+			if g.Output == "json" {
+				if err := writeJSON(cmd.OutOrStdout(), top); err != nil {
+					return err
+				}
+				return w.Err()
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, top.Name)
+			printTree(out, top.Children, "")
+			fmt.Fprintf(out, "\n%d 个目录，%d 个文件\n", dirs, files)
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().IntP("depth", "d", 0, "最大显示层级（0 表示不限制）")
+	cmd.Flags().Bool("dirs-only", false, "只显示目录")
+	return cmd
+}
+
+// printTree 递归输出子节点，prefix 为当前层级的缩进前缀
+func printTree(w io.Writer, nodes []*treeNode, prefix string) {
+	for i, n := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		name := n.Name
+		if n.Link != "" {
+			name += " -> " + n.Link
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, name)
+		printTree(w, n.Children, prefix+next)
+	}
+}
@@ -0,0 +1,247 @@
+// Package pathmatch 提供 gitignore 风格的路径匹配，供 Cobra 示例中的文件工具共用。
+//
+// 支持的语法：
+//   - 普通通配符 * ? [abc]，不跨越目录分隔符
+//   - ** 匹配零个或多个目录，如 **/vendor、build/**、a/**/b
+//   - 不含 / 的模式匹配任意层级的文件名，如 *.log、node_modules
+//   - 以 / 开头或中间含 / 的模式相对于扫描根目录匹配
+//   - 以 / 结尾的模式只匹配目录
+//   - 以 ! 开头的模式表示取反（仅用于忽略文件）
+//
+// 所有路径均为相对扫描根目录、以 / 分隔的路径。
+package pathmatch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Pattern 一条编译后的匹配规则
+type Pattern struct {
+	raw      string
+	segments []string // 按 / 拆分后的模式片段
+	negate   bool     // 以 ! 开头
+	dirOnly  bool     // 以 / 结尾，只匹配目录
+	anchored bool     // 相对根目录匹配；否则匹配任意层级
+}
+
+// Compile 编译一条模式
+func Compile(pattern string) (Pattern, error) {
+	p := Pattern{raw: pattern}
+
+	if strings.HasPrefix(pattern, "!") {
+		p.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		p.anchored = true
+		pattern = strings.TrimLeft(pattern, "/")
+	}
+	if strings.Contains(pattern, "/") {
+		p.anchored = true
+	}
+	if pattern == "" {
+		return p, fmt.Errorf("空模式 %q", p.raw)
+	}
+
+	p.segments = strings.Split(pattern, "/")
+	for _, seg := range p.segments {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return p, fmt.Errorf("无效的模式 %q: %w", p.raw, err)
+		}
+	}
+	return p, nil
+}
+
+// String 返回原始模式
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Match 判断相对路径是否匹配（不考虑取反）
+func (p Pattern) Match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	if p.anchored {
+		return matchSegments(p.segments, parts)
+	}
+	// 非锚定模式等价于在前面加 **/
+	for i := range parts {
+		if matchSegments(p.segments, parts[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments 逐段匹配，** 可以匹配零个或多个路径段
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// 合并连续的 **
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range parts {
+				if matchSegments(pattern, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// CompileAll 编译一组模式（用于 --include / --exclude，不允许取反）
+func CompileAll(patterns []string) ([]Pattern, error) {
+	result := make([]Pattern, 0, len(patterns))
+	for _, s := range patterns {
+		p, err := Compile(s)
+		if err != nil {
+			return nil, err
+		}
+		if p.negate {
+			return nil, fmt.Errorf("模式 %q 不支持 ! 取反，请使用 --include", s)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// anyMatch 任一模式匹配即返回 true
+func anyMatch(patterns []Pattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.Match(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignore 一组 gitignore 规则，后出现的规则优先
+type Ignore struct {
+	patterns []Pattern
+}
+
+// ParseIgnore 解析 gitignore 格式的内容：# 开头为注释，空行忽略
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	ig := &Ignore{}
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), " \t\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p, err := Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		ig.patterns = append(ig.patterns, p)
+	}
+	return ig, sc.Err()
+}
+
+// ParseIgnoreFile 读取并解析忽略文件
+func ParseIgnoreFile(name string) (*Ignore, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ig, err := ParseIgnore(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return ig, nil
+}
+
+// Ignored 按 gitignore 语义判断路径是否被忽略：最后一条匹配的规则决定结果
+func (ig *Ignore) Ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range ig.patterns {
+		if p.Match(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// Filter 组合 include、exclude 与忽略文件，供目录遍历使用
+type Filter struct {
+	Include []Pattern // 非空时只保留匹配的文件
+	Exclude []Pattern // 排除匹配的文件和目录
+	Ignore  *Ignore   // 可选的忽略规则
+}
+
+// NewFilter 由命令行参数构造过滤器，ignoreFile 为空表示不使用忽略文件
+func NewFilter(include, exclude []string, ignoreFile string) (*Filter, error) {
+	f := &Filter{}
+	var err error
+	if f.Include, err = CompileAll(include); err != nil {
+		return nil, err
+	}
+	if f.Exclude, err = CompileAll(exclude); err != nil {
+		return nil, err
+	}
+	if ignoreFile != "" {
+		if f.Ignore, err = ParseIgnoreFile(ignoreFile); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// SkipDir 遍历时是否跳过整个目录（不再进入）
+func (f *Filter) SkipDir(rel string) bool {
+	if f == nil {
+		return false
+	}
+	if anyMatch(f.Exclude, rel, true) {
+		return true
+	}
+	return f.Ignore != nil && f.Ignore.Ignored(rel, true)
+}
+
+// MatchFile 文件是否保留
+func (f *Filter) MatchFile(rel string) bool {
+	if f == nil {
+		return true
+	}
+	if anyMatch(f.Exclude, rel, false) {
+		return false
+	}
+	if f.Ignore != nil && f.Ignore.Ignored(rel, false) {
+		return false
+	}
+	return len(f.Include) == 0 || anyMatch(f.Include, rel, false)
+}
@@ -0,0 +1,90 @@
+// Package table 输出按列对齐的终端表格，正确处理中日韩等宽字符（占两列）。
+//
+// text/tabwriter 按字符数对齐，遇到中文列名或用户名时列会错位，这里按显示宽度计算。
+package table
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// Width 返回字符串在终端中的显示宽度：东亚宽字符和全角字符占两列
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// Table 一个简单的表格：一行表头加若干数据行
+type Table struct {
+	header []string
+	rows   [][]string
+	right  map[int]bool // 需要右对齐的列（通常是数字列）
+}
+
+// New 创建表格，参数为表头
+func New(header ...string) *Table {
+	return &Table{header: header, right: make(map[int]bool)}
+}
+
+// AlignRight 设置右对齐的列（从 0 开始）
+func (t *Table) AlignRight(cols ...int) *Table {
+	for _, c := range cols {
+		t.right[c] = true
+	}
+	return t
+}
+
+// Row 追加一行，单元格数量少于表头时补空
+func (t *Table) Row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Len 返回数据行数
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render 输出表格，列之间用两个空格分隔
+func (t *Table) Render(w io.Writer) error {
+	widths := make([]int, len(t.header))
+	all := append([][]string{t.header}, t.rows...)
+	for _, row := range all {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			widths[i] = max(widths[i], Width(row[i]))
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range all {
+		for i := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			pad := strings.Repeat(" ", widths[i]-Width(cell))
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			if t.right[i] {
+				b.WriteString(pad + cell)
+			} else if i < len(widths)-1 {
+				b.WriteString(cell + pad)
+			} else {
+				b.WriteString(cell) // 最后一列左对齐时不输出行尾空格
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}